@@ -0,0 +1,9 @@
+// Package swagger embeds the OpenAPI v2 documents generated by protoc-gen-swagger.
+package swagger
+
+import _ "embed"
+
+// FooServiceV1 is the OpenAPI v2 document of the v1 FooService HTTP/REST gateway.
+//
+//go:embed v1/foo-service.swagger.json
+var FooServiceV1 []byte
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/fooctl"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := fooctl.NewCommand().ExecuteContext(ctx); err != nil {
+		os.Exit(1)
+	}
+}
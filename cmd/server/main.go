@@ -8,7 +8,13 @@ import (
 )
 
 func main() {
-	if err := cmd.RunServer(); err != nil {
+	var err error
+	if len(os.Args) > 2 && os.Args[1] == "config" && os.Args[2] == "print" {
+		err = cmd.PrintConfig(os.Stdout, os.Args[3:], os.LookupEnv)
+	} else {
+		err = cmd.RunServer(os.Args[1:])
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,163 @@
+// Package apierrors builds gRPC status errors carrying google.rpc error details.
+// Every error includes an ErrorInfo with a stable reason code clients can switch
+// on; internal failures are logged and hidden behind a correlation ID.
+package apierrors
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/logger"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/requestid"
+)
+
+// Domain is the ErrorInfo domain of errors raised by this service.
+const Domain = "foo-service.wingkwong.github.com"
+
+// Stable reason codes reported in ErrorInfo.
+const (
+	ReasonInvalidArgument       = "INVALID_ARGUMENT"
+	ReasonUnsupportedAPIVersion = "UNSUPPORTED_API_VERSION"
+	ReasonNotFound              = "NOT_FOUND"
+	ReasonUnauthenticated       = "UNAUTHENTICATED"
+	ReasonPermissionDenied      = "PERMISSION_DENIED"
+	ReasonRateLimited           = "RATE_LIMITED"
+	ReasonDeadlineExceeded      = "DEADLINE_EXCEEDED"
+	ReasonCanceled              = "CANCELED"
+	ReasonDatabaseUnavailable   = "DATABASE_UNAVAILABLE"
+	ReasonInternal              = "INTERNAL"
+	ReasonPanic                 = "PANIC"
+)
+
+// CorrelationIDKey is the ErrorInfo metadata key holding the correlation ID of internal errors.
+const CorrelationIDKey = "correlation_id"
+
+const unavailableRetryDelay = time.Second
+
+// FieldViolation describes a single invalid request field, e.g. "foo.title".
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// New returns a status error with an ErrorInfo for reason followed by details.
+func New(code codes.Code, reason, msg string, metadata map[string]string, details ...proto.Message) error {
+	st := status.New(code, msg)
+	all := append([]proto.Message{&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   Domain,
+		Metadata: metadata,
+	}}, details...)
+	ds, err := st.WithDetails(all...)
+	if err != nil {
+		return st.Err()
+	}
+	return ds.Err()
+}
+
+// InvalidArgument reports a malformed request, listing the offending fields.
+func InvalidArgument(msg string, violations ...FieldViolation) error {
+	br := &errdetails.BadRequest{}
+	for _, v := range violations {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+	return New(codes.InvalidArgument, ReasonInvalidArgument, msg, nil, br)
+}
+
+// UnsupportedAPIVersion reports a request for an API version this server does not implement.
+func UnsupportedAPIVersion(want, got string) error {
+	return New(codes.Unimplemented, ReasonUnsupportedAPIVersion,
+		fmt.Sprintf("Unsupported API version: service API version '%s', but got '%s'", want, got),
+		map[string]string{"supported_version": want, "requested_version": got},
+		&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+			Field:       "api_version",
+			Description: fmt.Sprintf("must be '%s'", want),
+		}}},
+	)
+}
+
+// NotFound reports a missing resource of the given type and name.
+func NotFound(resourceType, name string) error {
+	return New(codes.NotFound, ReasonNotFound,
+		fmt.Sprintf("%s '%s' not found", resourceType, name), nil,
+		&errdetails.ResourceInfo{
+			ResourceType: resourceType,
+			ResourceName: name,
+			Description:  "The resource does not exist.",
+		},
+	)
+}
+
+// Unauthenticated reports a call without valid credentials.
+func Unauthenticated(msg string) error {
+	return New(codes.Unauthenticated, ReasonUnauthenticated, msg, nil)
+}
+
+// PermissionDenied reports a call the caller is not allowed to make.
+func PermissionDenied(msg string) error {
+	return New(codes.PermissionDenied, ReasonPermissionDenied, msg, nil)
+}
+
+// ResourceExhausted reports a rejected call the client may retry after delay.
+func ResourceExhausted(reason, msg string, delay time.Duration) error {
+	return New(codes.ResourceExhausted, reason, msg, nil,
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)},
+	)
+}
+
+// DeadlineExceeded reports a call that ran out of time before completing.
+func DeadlineExceeded(msg string) error {
+	return New(codes.DeadlineExceeded, ReasonDeadlineExceeded, msg, nil)
+}
+
+// Canceled reports a call abandoned by the client.
+func Canceled(msg string) error {
+	return New(codes.Canceled, ReasonCanceled, msg, nil)
+}
+
+// correlationID reuses the request ID so the log entry and the client-visible error match.
+func correlationID(ctx context.Context) string {
+	if id := requestid.FromContext(ctx); id != "" {
+		return id
+	}
+	return requestid.New()
+}
+
+func hidden(ctx context.Context, code codes.Code, reason, msg string, err error, fields []zap.Field, details ...proto.Message) error {
+	id := correlationID(ctx)
+	logger.FromContext(ctx).Error(msg, append([]zap.Field{
+		zap.String("correlation_id", id),
+		zap.String("reason", reason),
+		zap.Error(err),
+	}, fields...)...)
+	details = append(details, &errdetails.RequestInfo{RequestId: id})
+	return New(code, reason,
+		fmt.Sprintf("%s (correlation id: %s)", msg, id),
+		map[string]string{CorrelationIDKey: id},
+		details...,
+	)
+}
+
+// Internal logs err, with any extra fields, and returns an Internal error that
+// only exposes msg and a correlation ID.
+func Internal(ctx context.Context, reason, msg string, err error, fields ...zap.Field) error {
+	return hidden(ctx, codes.Internal, reason, msg, err, fields)
+}
+
+// Unavailable logs err and returns a retryable Unavailable error, e.g. when the database is unreachable.
+func Unavailable(ctx context.Context, reason, msg string, err error) error {
+	return hidden(ctx, codes.Unavailable, reason, msg, err, nil,
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(unavailableRetryDelay)},
+	)
+}
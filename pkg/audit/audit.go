@@ -0,0 +1,104 @@
+// Package audit keeps an append-only record of mutating and administrative calls,
+// separate from the debug logs, in the Audit table.
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// Entry is one audited call.
+type Entry struct {
+	ID        int64
+	Time      time.Time
+	Actor     string
+	Tenant    string
+	Peer      string
+	RequestID string
+	// Method is the full gRPC method, or the HTTP method and path of admin endpoints.
+	Method string
+	// FooID is the Foo targeted by the call, 0 if none.
+	FooID int64
+	// Code is the gRPC status code name of the outcome.
+	Code    string
+	Latency time.Duration
+}
+
+// Recorder persists audit entries.
+type Recorder interface {
+	Record(ctx context.Context, e Entry) error
+}
+
+// Query selects entries for Search. Zero fields do not filter.
+type Query struct {
+	Actor string
+	Start time.Time
+	End   time.Time
+	// AfterID resumes a search after the last entry of the previous page.
+	AfterID int64
+	Limit   int
+}
+
+// Store records entries in, and searches, the Audit table. It never updates or deletes rows.
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) Record(ctx context.Context, e Entry) error {
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO Audit(`Time`, `Actor`, `Tenant`, `Peer`, `RequestID`, `Method`, `FooID`, `Code`, `LatencyMicros`) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		e.Time, e.Actor, e.Tenant, e.Peer, e.RequestID, e.Method, e.FooID, e.Code, e.Latency.Microseconds())
+	return err
+}
+
+// Search returns the entries matching q in the order they were recorded.
+func (s *Store) Search(ctx context.Context, q Query) ([]Entry, error) {
+	var where []string
+	var args []interface{}
+	if len(q.Actor) > 0 {
+		where = append(where, "`Actor` = ?")
+		args = append(args, q.Actor)
+	}
+	if !q.Start.IsZero() {
+		where = append(where, "`Time` >= ?")
+		args = append(args, q.Start)
+	}
+	if !q.End.IsZero() {
+		where = append(where, "`Time` < ?")
+		args = append(args, q.End)
+	}
+	if q.AfterID > 0 {
+		where = append(where, "`ID` > ?")
+		args = append(args, q.AfterID)
+	}
+	query := "SELECT `ID`, `Time`, `Actor`, `Tenant`, `Peer`, `RequestID`, `Method`, `FooID`, `Code`, `LatencyMicros` FROM Audit"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY `ID` LIMIT ?"
+	args = append(args, q.Limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		var micros int64
+		if err := rows.Scan(&e.ID, &e.Time, &e.Actor, &e.Tenant, &e.Peer, &e.RequestID, &e.Method, &e.FooID, &e.Code, &micros); err != nil {
+			return nil, err
+		}
+		e.Latency = time.Duration(micros) * time.Microsecond
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,87 @@
+// Package buildinfo reports the version of the running binary. Version, Commit and
+// Date are set at build time, or else taken from what the Go toolchain recorded, e.g.
+//
+//	go build -ldflags "-X github.com/wingkwong/go-grpc-boilerplate/pkg/buildinfo.Version=v1.2.3 \
+//		-X github.com/wingkwong/go-grpc-boilerplate/pkg/buildinfo.Commit=$(git rev-parse HEAD) \
+//		-X github.com/wingkwong/go-grpc-boilerplate/pkg/buildinfo.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+var (
+	Version string
+	Commit  string
+	Date    string
+)
+
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	GoVersion string `json:"go_version"`
+	Module    string `json:"module,omitempty"`
+}
+
+// Get returns the build information. Values not set at build time fall back to the
+// module version and the VCS revision and time recorded by the Go toolchain.
+func Get() Info {
+	bi, _ := debug.ReadBuildInfo()
+	return get(bi)
+}
+
+func get(bi *debug.BuildInfo) Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+	}
+	if bi != nil {
+		info.Module = bi.Main.Path
+		if len(info.Version) == 0 {
+			info.Version = bi.Main.Version
+		}
+		revision, time := vcs(bi)
+		if len(info.Commit) == 0 {
+			info.Commit = revision
+		}
+		if len(info.Commit) == 0 {
+			info.Commit = pseudoVersionRevision(bi.Main.Version)
+		}
+		if len(info.Date) == 0 {
+			info.Date = time
+		}
+	}
+	if len(info.Version) == 0 {
+		info.Version = "(devel)"
+	}
+	return info
+}
+
+// pseudoVersionRevision returns the commit prefix of a pseudo-version such as
+// v0.0.0-20211102180033-6d9b8d2f1d0b, or "" for other versions.
+func pseudoVersionRevision(version string) string {
+	version = strings.TrimSuffix(version, "+incompatible")
+	i := strings.LastIndexByte(version, '-')
+	if i < 0 || len(version)-i-1 != 12 {
+		return ""
+	}
+	rev := version[i+1:]
+	for _, c := range rev {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return ""
+		}
+	}
+	timestamp := version[:i]
+	if j := strings.LastIndexAny(timestamp, "-."); j >= 0 {
+		timestamp = timestamp[j+1:]
+	}
+	if len(timestamp) != 14 {
+		return ""
+	}
+	return rev
+}
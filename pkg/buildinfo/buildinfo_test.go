@@ -0,0 +1,56 @@
+package buildinfo
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func Test_get(t *testing.T) {
+	tests := []struct {
+		name       string
+		commit     string
+		bi         *debug.BuildInfo
+		wantCommit string
+		wantVer    string
+	}{
+		{
+			name:       "01 - Pseudo-version",
+			bi:         &debug.BuildInfo{Main: debug.Module{Path: "example.com/foo", Version: "v0.0.0-20211102180033-6d9b8d2f1d0b"}},
+			wantCommit: "6d9b8d2f1d0b",
+			wantVer:    "v0.0.0-20211102180033-6d9b8d2f1d0b",
+		},
+		{
+			name:       "02 - Pre-release pseudo-version",
+			bi:         &debug.BuildInfo{Main: debug.Module{Path: "example.com/foo", Version: "v1.2.4-0.20211102180033-6d9b8d2f1d0b+incompatible"}},
+			wantCommit: "6d9b8d2f1d0b",
+			wantVer:    "v1.2.4-0.20211102180033-6d9b8d2f1d0b+incompatible",
+		},
+		{
+			name:    "03 - Release",
+			bi:      &debug.BuildInfo{Main: debug.Module{Path: "example.com/foo", Version: "v1.2.3"}},
+			wantVer: "v1.2.3",
+		},
+		{
+			name:       "04 - Commit set at build time",
+			commit:     "abc123",
+			bi:         &debug.BuildInfo{Main: debug.Module{Path: "example.com/foo", Version: "v0.0.0-20211102180033-6d9b8d2f1d0b"}},
+			wantCommit: "abc123",
+			wantVer:    "v0.0.0-20211102180033-6d9b8d2f1d0b",
+		},
+		{
+			name:    "05 - No build info",
+			wantVer: "(devel)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(c string) { Commit = c }(Commit)
+			Commit = tt.commit
+
+			got := get(tt.bi)
+			if got.Commit != tt.wantCommit || got.Version != tt.wantVer {
+				t.Errorf("get() = %+v, want commit %q and version %q", got, tt.wantCommit, tt.wantVer)
+			}
+		})
+	}
+}
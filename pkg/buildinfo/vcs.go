@@ -0,0 +1,19 @@
+//go:build go1.18
+// +build go1.18
+
+package buildinfo
+
+import "runtime/debug"
+
+// vcs returns the revision and commit time the Go toolchain stamped into the binary.
+func vcs(bi *debug.BuildInfo) (revision, time string) {
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.time":
+			time = s.Value
+		}
+	}
+	return revision, time
+}
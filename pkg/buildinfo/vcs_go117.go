@@ -0,0 +1,11 @@
+//go:build !go1.18
+// +build !go1.18
+
+package buildinfo
+
+import "runtime/debug"
+
+// vcs returns nothing, as toolchains before Go 1.18 do not stamp VCS information.
+func vcs(bi *debug.BuildInfo) (revision, time string) {
+	return "", ""
+}
@@ -0,0 +1,23 @@
+//go:build go1.18
+// +build go1.18
+
+package buildinfo
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func Test_get_vcs(t *testing.T) {
+	got := get(&debug.BuildInfo{
+		Main: debug.Module{Path: "example.com/foo", Version: "(devel)"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: "6d9b8d2f1d0b8a3c6f1e2d4b5a6c7d8e9f0a1b2c"},
+			{Key: "vcs.time", Value: "2021-11-02T18:00:33Z"},
+		},
+	})
+	if got.Commit != "6d9b8d2f1d0b8a3c6f1e2d4b5a6c7d8e9f0a1b2c" || got.Date != "2021-11-02T18:00:33Z" {
+		t.Errorf("get() = %+v, want the VCS revision and time", got)
+	}
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+)
+
+// AuditService searches the audit trail; it needs the server's admin token, see WithToken.
+type AuditService struct {
+	stub v1.AuditServiceClient
+}
+
+// AuditQuery selects audit entries; zero fields match everything.
+type AuditQuery struct {
+	Actor string
+	// Start and End bound the entry time to [Start, End).
+	Start, End time.Time
+	// PageSize is the number of entries fetched per call; the server defaults to 100.
+	PageSize int32
+	// PageToken resumes a search from an AuditIterator.PageToken.
+	PageToken string
+}
+
+// Search returns an iterator over the entries matching q in the order they were recorded.
+func (s *AuditService) Search(ctx context.Context, q AuditQuery) *AuditIterator {
+	req := &v1.SearchAuditRequest{ApiVersion: APIVersion, Actor: q.Actor, PageSize: q.PageSize}
+	if !q.Start.IsZero() {
+		req.StartTime = timestamppb.New(q.Start)
+	}
+	if !q.End.IsZero() {
+		req.EndTime = timestamppb.New(q.End)
+	}
+	it := &AuditIterator{}
+	it.pages = pager{token: q.PageToken, fetch: func(token string) (string, int, error) {
+		req.PageToken = token
+		resp, err := s.stub.Search(ctx, req)
+		if err != nil {
+			return "", 0, FromError(err)
+		}
+		it.page = resp.Entries
+		return resp.NextPageToken, len(resp.Entries), nil
+	}}
+	return it
+}
+
+// AuditIterator iterates over audit entries like FooIterator.
+type AuditIterator struct {
+	pages pager
+	page  []*v1.AuditEntry
+}
+
+// Next advances to the next entry, fetching the next page if needed.
+func (it *AuditIterator) Next() bool {
+	return it.pages.next()
+}
+
+// Entry returns the current entry.
+func (it *AuditIterator) Entry() *v1.AuditEntry {
+	return it.page[it.pages.i]
+}
+
+// Err returns the error that stopped the iteration, nil if it ran to the end.
+func (it *AuditIterator) Err() error {
+	return it.pages.err
+}
+
+// PageToken returns the token resuming the search after the current page, "" after the last.
+func (it *AuditIterator) PageToken() string {
+	return it.pages.token
+}
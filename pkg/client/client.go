@@ -0,0 +1,133 @@
+// Package client is a Go SDK for the Foo service. It wraps the generated gRPC stubs with
+// connection defaults, retries of transient failures, pagination iterators and typed errors:
+//
+//	c, err := client.New("foo.example.com:8080", client.WithToken(token))
+//	if err != nil {
+//		return err
+//	}
+//	defer c.Close()
+//
+//	it := c.Foos().List(ctx)
+//	for it.Next() {
+//		fmt.Println(it.Foo().Title)
+//	}
+//	if err := it.Err(); errors.Is(err, client.ErrUnavailable) {
+//		...
+//	}
+package client
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+)
+
+// APIVersion is the API version the client speaks.
+const APIVersion = "v1"
+
+const defaultTimeout = 10 * time.Second
+
+// Client is a connection to the Foo service; it is safe for concurrent use.
+type Client struct {
+	conn  *grpc.ClientConn
+	foos  *FooService
+	audit *AuditService
+}
+
+// New connects to target, a "host:port" resolved through DNS or any gRPC target URI, e.g.
+// "dns:///foo.example.com:8080". Calls are balanced across every address the target resolves
+// to. The connection is established lazily, so New only fails on invalid options.
+func New(target string, opts ...Option) (*Client, error) {
+	o := options{
+		timeout:  defaultTimeout,
+		retry:    DefaultRetryPolicy,
+		balancer: "round_robin",
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if !strings.Contains(target, ":///") {
+		target = "dns:///" + target
+	}
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"loadBalancingConfig": [{%q: {}}]}`, o.balancer)),
+	}
+	if o.insecure {
+		dialOpts = append(dialOpts, grpc.WithInsecure())
+	} else {
+		config := o.tls
+		if config == nil {
+			config = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(config)))
+	}
+
+	md := map[string]string{}
+	if o.token != "" {
+		md["authorization"] = "Bearer " + o.token
+	}
+	if o.apiKey != "" {
+		md["x-api-key"] = o.apiKey
+	}
+	if o.tenant != "" {
+		md["x-tenant-id"] = o.tenant
+	}
+	if len(md) > 0 {
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(callCredentials{md: md, insecure: o.insecure}))
+	}
+
+	var unary []grpc.UnaryClientInterceptor
+	if o.timeout > 0 {
+		unary = append(unary, timeoutInterceptor(o.timeout))
+	}
+	if o.retry.MaxAttempts > 1 {
+		unary = append(unary, o.retry.unaryInterceptor())
+	}
+	unary = append(unary, o.unary...)
+	dialOpts = append(dialOpts, grpc.WithChainUnaryInterceptor(unary...))
+	if len(o.stream) > 0 {
+		dialOpts = append(dialOpts, grpc.WithChainStreamInterceptor(o.stream...))
+	}
+	if o.userAgent != "" {
+		dialOpts = append(dialOpts, grpc.WithUserAgent(o.userAgent))
+	}
+	dialOpts = append(dialOpts, o.dialOpts...)
+
+	conn, err := grpc.Dial(target, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		conn:  conn,
+		foos:  &FooService{stub: v1.NewFooServiceClient(conn)},
+		audit: &AuditService{stub: v1.NewAuditServiceClient(conn)},
+	}, nil
+}
+
+// Close closes the connection; calls in flight fail with Canceled.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Conn returns the underlying connection, e.g. to create stubs of other services.
+func (c *Client) Conn() *grpc.ClientConn {
+	return c.conn
+}
+
+// Foos returns the Foo service.
+func (c *Client) Foos() *FooService {
+	return c.foos
+}
+
+// Audit returns the audit trail service.
+func (c *Client) Audit() *AuditService {
+	return c.audit
+}
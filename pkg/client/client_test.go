@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+)
+
+// fakeFooServer serves 1..total in pages and fails the first failures calls with Unavailable.
+type fakeFooServer struct {
+	v1.UnimplementedFooServiceServer
+	total    int64
+	failures int
+	calls    int
+	md       metadata.MD
+}
+
+func (s *fakeFooServer) unavailable(ctx context.Context) error {
+	s.calls++
+	s.md, _ = metadata.FromIncomingContext(ctx)
+	if s.calls <= s.failures {
+		return status.Error(codes.Unavailable, "try again")
+	}
+	return nil
+}
+
+func (s *fakeFooServer) Create(ctx context.Context, req *v1.CreateRequest) (*v1.CreateResponse, error) {
+	if err := s.unavailable(ctx); err != nil {
+		return nil, err
+	}
+	return &v1.CreateResponse{ApiVersion: APIVersion, Id: 1}, nil
+}
+
+func (s *fakeFooServer) Read(ctx context.Context, req *v1.ReadRequest) (*v1.ReadResponse, error) {
+	if err := s.unavailable(ctx); err != nil {
+		return nil, err
+	}
+	st, _ := status.New(codes.NotFound, "Foo '9' not found").WithDetails(
+		&errdetails.ErrorInfo{Reason: "NOT_FOUND"},
+		&errdetails.RequestInfo{RequestId: "req-1"},
+	)
+	return nil, st.Err()
+}
+
+func (s *fakeFooServer) ReadAll(ctx context.Context, req *v1.ReadAllRequest) (*v1.ReadAllResponse, error) {
+	if err := s.unavailable(ctx); err != nil {
+		return nil, err
+	}
+	after, _ := strconv.ParseInt(req.PageToken, 10, 64)
+	resp := &v1.ReadAllResponse{ApiVersion: APIVersion}
+	for id := after + 1; id <= s.total && int32(len(resp.Foos)) < req.PageSize; id++ {
+		resp.Foos = append(resp.Foos, &v1.Foo{Id: id})
+	}
+	if int32(len(resp.Foos)) == req.PageSize {
+		resp.NextPageToken = strconv.FormatInt(resp.Foos[len(resp.Foos)-1].Id, 10)
+	}
+	return resp, nil
+}
+
+func newTestClient(t *testing.T, srv *fakeFooServer, opts ...Option) *Client {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	v1.RegisterFooServiceServer(s, srv)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	opts = append([]Option{
+		WithInsecure(),
+		WithLoadBalancing("pick_first"),
+		WithRetry(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 2}),
+		WithDialOptions(grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		})),
+	}, opts...)
+	c, err := New("passthrough:///bufnet", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestFooService_List(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     int64
+		pageSize  int32
+		wantCalls int
+	}{
+		{name: "01 - Empty", total: 0, pageSize: 2, wantCalls: 1},
+		{name: "02 - Partial last page", total: 5, pageSize: 2, wantCalls: 3},
+		{name: "03 - Full last page", total: 4, pageSize: 2, wantCalls: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &fakeFooServer{total: tt.total}
+			c := newTestClient(t, srv)
+
+			var ids []int64
+			it := c.Foos().List(context.Background(), PageSize(tt.pageSize))
+			for it.Next() {
+				ids = append(ids, it.Foo().Id)
+			}
+			if err := it.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if int64(len(ids)) != tt.total || (tt.total > 0 && ids[len(ids)-1] != tt.total) {
+				t.Errorf("listed %v, want 1..%d", ids, tt.total)
+			}
+			if srv.calls != tt.wantCalls {
+				t.Errorf("made %d calls, want %d", srv.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		call      func(c *Client) error
+		wantCalls int
+		wantErr   error
+	}{
+		{
+			name:     "01 - Retries Unavailable",
+			failures: 2,
+			call: func(c *Client) error {
+				it := c.Foos().List(context.Background())
+				it.Next()
+				return it.Err()
+			},
+			wantCalls: 3,
+		},
+		{
+			name:     "02 - Gives up after MaxAttempts",
+			failures: 3,
+			call: func(c *Client) error {
+				it := c.Foos().List(context.Background())
+				it.Next()
+				return it.Err()
+			},
+			wantCalls: 3,
+			wantErr:   ErrUnavailable,
+		},
+		{
+			name:     "03 - Never retries Create",
+			failures: 1,
+			call: func(c *Client) error {
+				_, err := c.Foos().Create(context.Background(), &v1.Foo{Title: "t"})
+				return err
+			},
+			wantCalls: 1,
+			wantErr:   ErrUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &fakeFooServer{failures: tt.failures}
+			c := newTestClient(t, srv)
+			if err := tt.call(c); !errors.Is(err, tt.wantErr) && !(err == nil && tt.wantErr == nil) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if srv.calls != tt.wantCalls {
+				t.Errorf("made %d calls, want %d", srv.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestTypedErrorsAndMetadata(t *testing.T) {
+	srv := &fakeFooServer{}
+	c := newTestClient(t, srv, WithToken("t0k3n"), WithAPIKey("key"), WithTenant("acme"))
+
+	_, err := c.Foos().Get(context.Background(), 9)
+	if !errors.Is(err, ErrNotFound) || errors.Is(err, ErrInternal) {
+		t.Fatalf("error = %v, want ErrNotFound", err)
+	}
+	var e *Error
+	if !errors.As(err, &e) || e.Reason != "NOT_FOUND" || e.RequestID != "req-1" {
+		t.Errorf("unexpected error details %+v", e)
+	}
+	if Code(err) != codes.NotFound || status.Code(err) != codes.NotFound {
+		t.Errorf("Code = %v, want NotFound", Code(err))
+	}
+
+	for k, want := range map[string]string{"authorization": "Bearer t0k3n", "x-api-key": "key", "x-tenant-id": "acme"} {
+		if got := srv.md.Get(k); len(got) != 1 || got[0] != want {
+			t.Errorf("metadata %s = %v, want %q", k, got, want)
+		}
+	}
+}
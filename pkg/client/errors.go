@@ -0,0 +1,103 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Sentinel errors to test returned errors against with errors.Is, e.g.
+// errors.Is(err, client.ErrNotFound).
+var (
+	ErrInvalidArgument  = &Error{Code: codes.InvalidArgument}
+	ErrNotFound         = &Error{Code: codes.NotFound}
+	ErrUnauthenticated  = &Error{Code: codes.Unauthenticated}
+	ErrPermissionDenied = &Error{Code: codes.PermissionDenied}
+	ErrRateLimited      = &Error{Code: codes.ResourceExhausted}
+	ErrDeadlineExceeded = &Error{Code: codes.DeadlineExceeded}
+	ErrCanceled         = &Error{Code: codes.Canceled}
+	ErrUnavailable      = &Error{Code: codes.Unavailable}
+	ErrUnimplemented    = &Error{Code: codes.Unimplemented}
+	ErrInternal         = &Error{Code: codes.Internal}
+)
+
+// FieldViolation describes a single invalid request field, e.g. "foo.title".
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// Error is a failed call, decoded from the status and error details returned by the server.
+type Error struct {
+	Code    codes.Code
+	Message string
+	// Reason is the server's stable reason code, e.g. "NOT_FOUND" or "RATE_LIMITED".
+	Reason string
+	// RequestID is the correlation ID of internal errors, to quote when reporting them.
+	RequestID string
+	// RetryAfter is how long the server asked to wait before retrying, 0 if unspecified.
+	RetryAfter time.Duration
+	Violations []FieldViolation
+}
+
+func (e *Error) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("%s (%s): %s", e.Code, e.Reason, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Is matches sentinels by code, so every NotFound error is ErrNotFound.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// GRPCStatus lets status.FromError and status.Code see through an Error.
+func (e *Error) GRPCStatus() *status.Status {
+	return status.New(e.Code, e.Message)
+}
+
+// Code returns the status code of err, codes.OK for nil and codes.Unknown for non-gRPC errors.
+func Code(err error) codes.Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return status.Code(err)
+}
+
+// FromError converts a gRPC status error into an *Error, returning other errors unchanged.
+func FromError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	e = &Error{Code: st.Code(), Message: st.Message()}
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			e.Reason = d.GetReason()
+		case *errdetails.RequestInfo:
+			e.RequestID = d.GetRequestId()
+		case *errdetails.RetryInfo:
+			e.RetryAfter = d.GetRetryDelay().AsDuration()
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				e.Violations = append(e.Violations, FieldViolation{Field: v.GetField(), Description: v.GetDescription()})
+			}
+		}
+	}
+	return e
+}
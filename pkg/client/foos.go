@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+)
+
+// defaultPageSize is the number of Foos List fetches per call unless PageSize is given.
+const defaultPageSize = 100
+
+// FooService manages Foos. Its methods return *Error for failed calls.
+type FooService struct {
+	stub v1.FooServiceClient
+}
+
+// Create creates foo and returns its ID. It is never retried, so a failure may still have
+// created it.
+func (s *FooService) Create(ctx context.Context, foo *v1.Foo) (int64, error) {
+	resp, err := s.stub.Create(ctx, &v1.CreateRequest{ApiVersion: APIVersion, Foo: foo})
+	if err != nil {
+		return 0, FromError(err)
+	}
+	return resp.Id, nil
+}
+
+// Get returns the Foo with the given ID.
+func (s *FooService) Get(ctx context.Context, id int64) (*v1.Foo, error) {
+	resp, err := s.stub.Read(ctx, &v1.ReadRequest{ApiVersion: APIVersion, Id: id})
+	if err != nil {
+		return nil, FromError(err)
+	}
+	return resp.Foo, nil
+}
+
+// Update replaces the title and description of the Foo with foo.Id.
+func (s *FooService) Update(ctx context.Context, foo *v1.Foo) error {
+	_, err := s.stub.Update(ctx, &v1.UpdateRequest{ApiVersion: APIVersion, Foo: foo})
+	return FromError(err)
+}
+
+// Delete deletes the Foo with the given ID.
+func (s *FooService) Delete(ctx context.Context, id int64) error {
+	_, err := s.stub.Delete(ctx, &v1.DeleteRequest{ApiVersion: APIVersion, Id: id})
+	return FromError(err)
+}
+
+type listOptions struct {
+	pageSize  int32
+	pageToken string
+}
+
+// ListOption configures List.
+type ListOption func(*listOptions)
+
+// PageSize sets how many Foos are fetched per call; the default is 100.
+func PageSize(n int32) ListOption {
+	return func(o *listOptions) {
+		o.pageSize = n
+	}
+}
+
+// PageToken resumes a listing from a FooIterator.PageToken.
+func PageToken(token string) ListOption {
+	return func(o *listOptions) {
+		o.pageToken = token
+	}
+}
+
+// List returns an iterator over every Foo in ID order, fetching pages as needed.
+func (s *FooService) List(ctx context.Context, opts ...ListOption) *FooIterator {
+	o := listOptions{pageSize: defaultPageSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	it := &FooIterator{}
+	it.pages = pager{token: o.pageToken, fetch: func(token string) (string, int, error) {
+		resp, err := s.stub.ReadAll(ctx, &v1.ReadAllRequest{ApiVersion: APIVersion, PageSize: o.pageSize, PageToken: token})
+		if err != nil {
+			return "", 0, FromError(err)
+		}
+		it.page = resp.Foos
+		return resp.NextPageToken, len(resp.Foos), nil
+	}}
+	return it
+}
+
+// FooIterator iterates over Foos:
+//
+//	for it.Next() {
+//		foo := it.Foo()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type FooIterator struct {
+	pages pager
+	page  []*v1.Foo
+}
+
+// Next advances to the next Foo, fetching the next page if needed. It returns false when
+// there are no more Foos or a call failed.
+func (it *FooIterator) Next() bool {
+	return it.pages.next()
+}
+
+// Foo returns the current Foo.
+func (it *FooIterator) Foo() *v1.Foo {
+	return it.page[it.pages.i]
+}
+
+// Err returns the error that stopped the iteration, nil if it ran to the end.
+func (it *FooIterator) Err() error {
+	return it.pages.err
+}
+
+// PageToken returns the token resuming the listing after the current page, "" after the last.
+func (it *FooIterator) PageToken() string {
+	return it.pages.token
+}
+
+// pager walks the items of consecutive pages; fetch loads the page after token and returns
+// the next token and the number of items loaded.
+type pager struct {
+	fetch   func(token string) (string, int, error)
+	token   string
+	started bool
+	n, i    int
+	err     error
+}
+
+func (p *pager) next() bool {
+	if p.err != nil {
+		return false
+	}
+	p.i++
+	for p.i >= p.n {
+		if p.started && p.token == "" {
+			return false
+		}
+		p.started = true
+		p.token, p.n, p.err = p.fetch(p.token)
+		p.i = 0
+		if p.err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+type options struct {
+	tls       *tls.Config
+	insecure  bool
+	token     string
+	apiKey    string
+	tenant    string
+	timeout   time.Duration
+	retry     RetryPolicy
+	unary     []grpc.UnaryClientInterceptor
+	stream    []grpc.StreamClientInterceptor
+	balancer  string
+	dialOpts  []grpc.DialOption
+	userAgent string
+}
+
+// Option configures a Client.
+type Option func(*options)
+
+// WithTLS connects over TLS; a nil config uses the system roots. This is the default.
+func WithTLS(config *tls.Config) Option {
+	return func(o *options) {
+		o.tls = config
+		o.insecure = false
+	}
+}
+
+// WithInsecure connects without TLS, e.g. to a local server.
+func WithInsecure() Option {
+	return func(o *options) {
+		o.insecure = true
+	}
+}
+
+// WithToken sends token as "authorization: Bearer <token>" on every call, e.g. the admin
+// token needed by Audit().Search.
+func WithToken(token string) Option {
+	return func(o *options) {
+		o.token = token
+	}
+}
+
+// WithAPIKey identifies the caller to the server's rate limiter.
+func WithAPIKey(key string) Option {
+	return func(o *options) {
+		o.apiKey = key
+	}
+}
+
+// WithTenant names the caller's tenant in the audit trail.
+func WithTenant(tenant string) Option {
+	return func(o *options) {
+		o.tenant = tenant
+	}
+}
+
+// WithTimeout bounds every call whose context has no deadline. Zero disables it; the default is 10s.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithRetry replaces the retry policy; a zero policy disables retries.
+func WithRetry(p RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = p
+	}
+}
+
+// WithUnaryInterceptors adds interceptors run, in order, around every unary call.
+func WithUnaryInterceptors(interceptors ...grpc.UnaryClientInterceptor) Option {
+	return func(o *options) {
+		o.unary = append(o.unary, interceptors...)
+	}
+}
+
+// WithStreamInterceptors adds interceptors run, in order, around every streaming call.
+func WithStreamInterceptors(interceptors ...grpc.StreamClientInterceptor) Option {
+	return func(o *options) {
+		o.stream = append(o.stream, interceptors...)
+	}
+}
+
+// WithLoadBalancing selects the gRPC load balancing policy across the addresses the target
+// resolves to, e.g. "round_robin" (the default) or "pick_first".
+func WithLoadBalancing(policy string) Option {
+	return func(o *options) {
+		o.balancer = policy
+	}
+}
+
+// WithUserAgent sets the user agent reported to the server.
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithDialOptions passes further options to grpc.Dial.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOpts = append(o.dialOpts, opts...)
+	}
+}
+
+// callCredentials attaches the configured token, API key and tenant to every call.
+type callCredentials struct {
+	md       map[string]string
+	insecure bool
+}
+
+func (c callCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return c.md, nil
+}
+
+// RequireTransportSecurity refuses to send a token over plaintext unless WithInsecure was chosen.
+func (c callCredentials) RequireTransportSecurity() bool {
+	return !c.insecure
+}
+
+var _ credentials.PerRPCCredentials = callCredentials{}
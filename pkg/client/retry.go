@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryPolicy retries calls failing with Unavailable, or with ResourceExhausted when the
+// server says when to retry, with exponential backoff and jitter. Create is never retried,
+// since it is not idempotent.
+type RetryPolicy struct {
+	// MaxAttempts includes the first attempt; 1 or less disables retries.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+}
+
+// DefaultRetryPolicy is used unless WithRetry is given.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	Multiplier:     2,
+}
+
+var nonIdempotentMethods = map[string]bool{
+	"/v1.FooService/Create": true,
+}
+
+// backoff returns the delay before the given retry, counting from 1.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(retry-1))
+	if max := float64(p.MaxBackoff); p.MaxBackoff > 0 && d > max {
+		d = max
+	}
+	// Jitter spreads out clients retrying after the same failure.
+	return time.Duration(d/2 + rand.Float64()*d/2)
+}
+
+// retryDelay returns how long to wait before retrying err, or false if it must not be retried.
+func (p RetryPolicy) retryDelay(err error, retry int) (time.Duration, bool) {
+	st := status.Convert(err)
+	var hint time.Duration
+	for _, d := range st.Details() {
+		if ri, ok := d.(*errdetails.RetryInfo); ok {
+			hint = ri.GetRetryDelay().AsDuration()
+		}
+	}
+	switch {
+	case st.Code() == codes.Unavailable:
+	case st.Code() == codes.ResourceExhausted && hint > 0:
+	default:
+		return 0, false
+	}
+	if d := p.backoff(retry); d > hint {
+		return d, true
+	}
+	return hint, true
+}
+
+func (p RetryPolicy) unaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if nonIdempotentMethods[method] {
+			return err
+		}
+		for retry := 1; err != nil && retry < p.MaxAttempts; retry++ {
+			delay, ok := p.retryDelay(err, retry)
+			if !ok {
+				return err
+			}
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+				return err
+			}
+			t := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return err
+			case <-t.C:
+			}
+			err = invoker(ctx, method, req, reply, cc, opts...)
+		}
+		return err
+	}
+}
+
+// timeoutInterceptor applies d to calls whose context has no deadline.
+func timeoutInterceptor(d time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -0,0 +1,584 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/logger"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/admin"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/cors"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/grpc/middleware"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/payload"
+	restmiddleware "github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/rest/middleware"
+)
+
+// EnvPrefix prefixes the environment variable of every setting, e.g. FOO_DB_PASSWORD for -db-password.
+const EnvPrefix = "FOO_"
+
+const redacted = "<redacted>"
+
+// secretSettings are never printed.
+var secretSettings = map[string]bool{"db-password": true, "admin-token": true, "rate-limit-api-keys": true}
+
+type Config struct {
+	ConfigFile              string
+	ConfigReloadInterval    time.Duration
+	Port                    string
+	GRPCPort                string
+	HTTPPort                string
+	AdminPort               string
+	AdminBind               string
+	AdminToken              string
+	Audit                   bool
+	DatastoreDBHost         string
+	DatastoreDBUser         string
+	DatastoreDBPassword     string
+	DatastoreDBPasswordFile string
+	DatastoreDBSchema       string
+	LogLevel                int
+	LogPackageLevels        string
+	LogFormat               string
+	LogFile                 string
+	LogMaxSizeMB            int
+	LogMaxAgeDays           int
+	LogMaxBackups           int
+	LogCompress             bool
+	LogRotateInterval       time.Duration
+	LogSampleInitial        int
+	LogSampleThereafter     int
+	LogPayloads             bool
+	LogPayloadRedact        string
+	LogPayloadMaxBytes      int
+	LogPayloadSampleRate    float64
+	RateLimit               string
+	MethodRateLimits        string
+	RateLimitAPIKeys        string
+	TrustedProxies          string
+	ShutdownTimeout         time.Duration
+	ShutdownDelay           time.Duration
+	DefaultTimeout          time.Duration
+	MaxTimeout              time.Duration
+	MethodTimeouts          string
+	TraceExporter           string
+	TraceOTLPEndpoint       string
+	TraceOTLPInsecure       bool
+	TraceFile               string
+	TraceSampleRatio        float64
+	GRPCWebOrigins          string
+	CORSOrigins             string
+	CORSMethods             string
+	CORSHeaders             string
+	CORSCredentials         bool
+	CORSMaxAge              time.Duration
+	DocsScriptURL           string
+	GRPCMaxRecvMsgSize      int
+	GRPCMaxSendMsgSize      int
+	GRPCMaxStreams          uint
+	GRPCKeepaliveMin        time.Duration
+	GRPCKeepaliveIdle       bool
+}
+
+// newFlagSet registers every setting as a flag bound to cfg, storing the defaults in cfg.
+func newFlagSet(cfg *Config) *flag.FlagSet {
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	fs.StringVar(&cfg.ConfigFile, "config", "", "YAML (.yaml, .yml) or TOML (.toml) file to load settings from")
+	fs.DurationVar(&cfg.ConfigReloadInterval, "config-reload-interval", 5*time.Second, "How often to check the -config file for changes to apply live, 0 to reload only on SIGHUP")
+	fs.StringVar(&cfg.Port, "port", "", "Single port serving gRPC, gRPC-Web and the HTTP gateway, instead of -grpc-port and -http-port")
+	fs.StringVar(&cfg.GRPCPort, "grpc-port", "", "gRPC port to bind")
+	fs.StringVar(&cfg.HTTPPort, "http-port", "", "HTTP port to bind")
+	fs.StringVar(&cfg.AdminPort, "admin-port", "", "Admin HTTP port serving /metrics, /admin/* and /debug/pprof/, empty to disable")
+	fs.StringVar(&cfg.AdminBind, "admin-bind", "", "Interface address the admin port binds to, e.g. 127.0.0.1; empty for every interface")
+	fs.StringVar(&cfg.AdminToken, "admin-token", "", "Bearer token required by the admin endpoints except /metrics and by audit searches; prefer "+EnvPrefix+"ADMIN_TOKEN")
+	fs.BoolVar(&cfg.Audit, "audit", false, "Record Create, Update, Delete and admin calls in the Audit table")
+	fs.StringVar(&cfg.DatastoreDBHost, "db-host", "", "Database host")
+	fs.StringVar(&cfg.DatastoreDBUser, "db-user", "", "Database user")
+	fs.StringVar(&cfg.DatastoreDBPassword, "db-password", "", "Database password; prefer -db-password-file or "+EnvPrefix+"DB_PASSWORD")
+	fs.StringVar(&cfg.DatastoreDBPasswordFile, "db-password-file", "", "File containing the database password, e.g. a mounted secret")
+	fs.StringVar(&cfg.DatastoreDBSchema, "db-schema", "", "Database schema")
+	fs.IntVar(&cfg.LogLevel, "log-level", 0, "Global log level: Debug(-1), Info(0), Warn(1), Error(2), DPanic(3), Panic(4), Fatal(5)")
+	fs.StringVar(&cfg.LogPackageLevels, "log-package-levels", "", "Per-package log levels overriding -log-level, e.g. grpc=-1,rest=warn")
+	fs.StringVar(&cfg.LogFormat, "log-format", logger.FormatJSON, "Log encoding: json, or console for development")
+	fs.StringVar(&cfg.LogFile, "log-file", "", "File to write logs to instead of stdout and stderr")
+	fs.IntVar(&cfg.LogMaxSizeMB, "log-max-size-mb", 100, "Size in megabytes at which -log-file is rotated")
+	fs.IntVar(&cfg.LogMaxAgeDays, "log-max-age-days", 0, "Days to keep rotated log files, 0 to keep them regardless of age")
+	fs.IntVar(&cfg.LogMaxBackups, "log-max-backups", 0, "Number of rotated log files to keep, 0 to keep them all")
+	fs.BoolVar(&cfg.LogCompress, "log-compress", false, "Gzip rotated log files")
+	fs.DurationVar(&cfg.LogRotateInterval, "log-rotate-interval", 0, "Also rotate -log-file at this interval, e.g. 24h, 0 to rotate by size only")
+	fs.IntVar(&cfg.LogSampleInitial, "log-sample-initial", 0, "Debug entries with the same message logged each second before sampling starts, 0 to disable sampling")
+	fs.IntVar(&cfg.LogSampleThereafter, "log-sample-thereafter", 100, "Once sampling, log every Nth debug entry with the same message")
+	fs.BoolVar(&cfg.LogPayloads, "log-payloads", false, "Log gRPC messages and REST bodies, with fields marked (sensitive) in the protos redacted")
+	fs.StringVar(&cfg.LogPayloadRedact, "log-payload-redact", "", "Further field paths to redact in logged payloads, e.g. foo.title,api_version")
+	fs.IntVar(&cfg.LogPayloadMaxBytes, "log-payload-max-bytes", 4096, "Truncate logged payloads to this many bytes, 0 for no limit")
+	fs.Float64Var(&cfg.LogPayloadSampleRate, "log-payload-sample-rate", 1, "Fraction of calls whose payloads are logged")
+	fs.StringVar(&cfg.RateLimit, "rate-limit", "", "Default per-caller rate limit as RPS[:BURST], empty to disable")
+	fs.StringVar(&cfg.MethodRateLimits, "method-rate-limits", "", "Per-method rate limits, e.g. /v1.FooService/ReadAll=5:10,/v1.FooService/Create=20")
+	fs.StringVar(&cfg.RateLimitAPIKeys, "rate-limit-api-keys", "", "API keys rate limited on their own; callers sending other keys are limited by address")
+	fs.StringVar(&cfg.TrustedProxies, "trusted-proxies", "127.0.0.0/8,::1", "Networks of proxies, e.g. load balancers, whose X-Forwarded-For entries identify the client")
+	fs.DurationVar(&cfg.DefaultTimeout, "default-timeout", 10*time.Second, "Deadline applied to calls sent without one, 0 to disable")
+	fs.DurationVar(&cfg.MaxTimeout, "max-timeout", 30*time.Second, "Longest deadline a client may request, 0 for no limit")
+	fs.StringVar(&cfg.MethodTimeouts, "method-timeouts", "", "Per-method timeouts as DEFAULT[:MAX], e.g. /v1.FooService/ReadAll=5s:20s")
+	fs.DurationVar(&cfg.ShutdownTimeout, "shutdown-timeout", 15*time.Second, "Maximum time to drain the HTTP and gRPC servers on shutdown")
+	fs.DurationVar(&cfg.ShutdownDelay, "shutdown-delay", 0, "Time /readyz fails before the servers are drained on shutdown, so load balancers stop routing to them")
+	fs.StringVar(&cfg.TraceExporter, "trace-exporter", "", "Trace exporter: otlp, stdout, or empty to disable tracing")
+	fs.StringVar(&cfg.TraceOTLPEndpoint, "trace-otlp-endpoint", "localhost:4317", "OTLP gRPC collector endpoint")
+	fs.BoolVar(&cfg.TraceOTLPInsecure, "trace-otlp-insecure", false, "Connect to the OTLP collector without TLS")
+	fs.StringVar(&cfg.TraceFile, "trace-file", "", "File the stdout exporter appends spans to instead of stdout")
+	fs.Float64Var(&cfg.TraceSampleRatio, "trace-sample-ratio", 1, "Fraction of new traces to sample, between 0 and 1")
+	fs.StringVar(&cfg.GRPCWebOrigins, "grpc-web-allowed-origins", "", "Origins allowed to make cross-origin gRPC-Web calls, e.g. https://app.example.com,https://*.example.com, or * for any")
+	fs.StringVar(&cfg.CORSOrigins, "cors-allowed-origins", "", "Origins allowed to call the HTTP gateway, e.g. https://app.example.com,https://*.example.com, or * for any; empty disables CORS")
+	fs.StringVar(&cfg.CORSMethods, "cors-allowed-methods", "GET,POST,PUT,PATCH,DELETE", "Methods allowed in cross-origin gateway requests")
+	fs.StringVar(&cfg.CORSHeaders, "cors-allowed-headers", "Content-Type,Authorization,X-Api-Key,X-Tenant-Id,X-Request-Id,X-Request-Timeout", "Request headers allowed in cross-origin gateway requests, or * for any")
+	fs.BoolVar(&cfg.CORSCredentials, "cors-allow-credentials", false, "Allow cross-origin gateway requests with cookies or HTTP authentication")
+	fs.DurationVar(&cfg.CORSMaxAge, "cors-max-age", 10*time.Minute, "How long browsers may cache preflight responses")
+	fs.StringVar(&cfg.DocsScriptURL, "docs-script-url", "", "URL of the Redoc bundle loaded by the /docs page instead of the embedded copy, e.g. a CDN")
+	fs.IntVar(&cfg.GRPCMaxRecvMsgSize, "grpc-max-recv-msg-size", 4<<20, "Largest gRPC request message in bytes")
+	fs.IntVar(&cfg.GRPCMaxSendMsgSize, "grpc-max-send-msg-size", 16<<20, "Largest gRPC response message in bytes")
+	fs.UintVar(&cfg.GRPCMaxStreams, "grpc-max-concurrent-streams", 0, "Maximum concurrent streams per HTTP/2 connection, 0 for the default")
+	fs.DurationVar(&cfg.GRPCKeepaliveMin, "grpc-keepalive-min-time", 5*time.Minute, "Shortest client keepalive ping interval tolerated before closing the connection")
+	fs.BoolVar(&cfg.GRPCKeepaliveIdle, "grpc-keepalive-permit-without-stream", false, "Allow client keepalive pings on connections without active RPCs")
+
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "Usage:\n  server [flags]\n  server config print [flags]\n\n")
+		fmt.Fprintf(out, "Settings are read from defaults, then the -config file, then %s* environment\n", EnvPrefix)
+		fmt.Fprintf(out, "variables, then flags; later sources win. The file and environment use the flag\n")
+		fmt.Fprintf(out, "names, e.g. 'db-host: localhost:3306' or %sDB_HOST=localhost:3306.\n\nFlags:\n", EnvPrefix)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// envName is the environment variable of the setting name, e.g. FOO_DB_HOST for db-host.
+func envName(name string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// LoadConfig builds the configuration from defaults, the config file, environment
+// variables and args, in increasing order of precedence, and validates it.
+// lookupEnv is usually os.LookupEnv.
+func LoadConfig(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	cfg, _, err := loadConfig(args, lookupEnv)
+	return cfg, err
+}
+
+// loadConfig is LoadConfig, also returning the flag set bound to the config.
+func loadConfig(args []string, lookupEnv func(string) (string, bool)) (*Config, *flag.FlagSet, error) {
+	cfg := &Config{}
+	fs := newFlagSet(cfg)
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, nil, fmt.Errorf("[ERROR] Unexpected arguments: %v", fs.Args())
+	}
+
+	explicit := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = f.Value.String()
+	})
+
+	if len(cfg.ConfigFile) == 0 {
+		cfg.ConfigFile, _ = lookupEnv(envName("config"))
+	}
+	if len(cfg.ConfigFile) > 0 {
+		if err := loadFile(fs, cfg.ConfigFile); err != nil {
+			return nil, nil, fmt.Errorf("[ERROR] Failed to load config file: %v", err)
+		}
+	}
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		v, ok := lookupEnv(envName(f.Name))
+		if !ok || err != nil || f.Name == "config" {
+			return
+		}
+		if setErr := fs.Set(f.Name, v); setErr != nil {
+			err = fmt.Errorf("[ERROR] Invalid value %q for %s: %v", v, envName(f.Name), setErr)
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for name, v := range explicit {
+		if err := fs.Set(name, v); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(cfg.DatastoreDBPasswordFile) > 0 {
+		if len(cfg.DatastoreDBPassword) > 0 {
+			return nil, nil, fmt.Errorf("[ERROR] Set only one of db-password and db-password-file")
+		}
+		b, err := ioutil.ReadFile(cfg.DatastoreDBPasswordFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("[ERROR] Failed to read db-password-file: %v", err)
+		}
+		cfg.DatastoreDBPassword = strings.TrimRight(string(b), "\r\n")
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, fs, nil
+}
+
+// loadFile sets the flags named by the keys of a YAML or TOML file. Nested tables are
+// joined with "-", so db: {host: x} sets db-host, and lists are joined with ",".
+func loadFile(fs *flag.FlagSet, path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var values map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(b, &values)
+	case ".toml":
+		_, err = toml.Decode(string(b), &values)
+	default:
+		return fmt.Errorf("unsupported config file extension '%s', want .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	settings := map[string]string{}
+	if err := flatten("", values, settings); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	var errs []string
+	for _, name := range sortedKeys(settings) {
+		if name == "config" {
+			errs = append(errs, "'config' cannot be set from the config file")
+			continue
+		}
+		if fs.Lookup(name) == nil {
+			errs = append(errs, unknownSetting(fs, name))
+			continue
+		}
+		if err := fs.Set(name, settings[name]); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid value %q for '%s': %v", settings[name], name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s:\n  - %s", path, strings.Join(errs, "\n  - "))
+	}
+	return nil
+}
+
+func flatten(prefix string, in interface{}, out map[string]string) error {
+	switch v := in.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if err := flatten(join(prefix, k), child, out); err != nil {
+				return err
+			}
+		}
+	case map[interface{}]interface{}:
+		for k, child := range v {
+			if err := flatten(join(prefix, fmt.Sprint(k)), child, out); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		out[prefix] = strings.Join(items, ",")
+	case nil:
+		out[prefix] = ""
+	default:
+		out[prefix] = fmt.Sprint(v)
+	}
+	return nil
+}
+
+func join(prefix, key string) string {
+	if len(prefix) == 0 {
+		return key
+	}
+	return prefix + "-" + key
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// unknownSetting describes an unknown key, suggesting the closest setting name.
+func unknownSetting(fs *flag.FlagSet, name string) string {
+	best, bestDist := "", 3
+	fs.VisitAll(func(f *flag.Flag) {
+		if d := editDistance(name, f.Name); d < bestDist {
+			best, bestDist = f.Name, d
+		}
+	})
+	if len(best) > 0 {
+		return fmt.Sprintf("unknown setting '%s', did you mean '%s'?", name, best)
+	}
+	return fmt.Sprintf("unknown setting '%s'", name)
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func minInt(v int, vs ...int) int {
+	for _, x := range vs {
+		if x < v {
+			v = x
+		}
+	}
+	return v
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []string
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+
+	if len(c.Port) > 0 {
+		if len(c.GRPCPort) > 0 || len(c.HTTPPort) > 0 {
+			add("port cannot be combined with grpc-port or http-port")
+		}
+	} else {
+		if len(c.GRPCPort) == 0 {
+			add("grpc-port is required unless port is set")
+		}
+		if len(c.HTTPPort) == 0 {
+			add("http-port is required unless port is set")
+		}
+	}
+	if c.LogLevel < -1 || c.LogLevel > 5 {
+		add("log-level must be between -1 and 5, got %d", c.LogLevel)
+	}
+	if _, err := c.logging(); err != nil {
+		add("%v", err)
+	}
+	if c.LogPayloadMaxBytes < 0 {
+		add("log-payload-max-bytes must not be negative, got %d", c.LogPayloadMaxBytes)
+	}
+	if c.LogPayloadSampleRate < 0 || c.LogPayloadSampleRate > 1 {
+		add("log-payload-sample-rate must be between 0 and 1, got %v", c.LogPayloadSampleRate)
+	}
+	if c.ConfigReloadInterval < 0 {
+		add("config-reload-interval must not be negative, got %v", c.ConfigReloadInterval)
+	}
+	if c.ShutdownTimeout <= 0 {
+		add("shutdown-timeout must be positive, got %v", c.ShutdownTimeout)
+	}
+	if c.ShutdownDelay < 0 {
+		add("shutdown-delay must not be negative, got %v", c.ShutdownDelay)
+	}
+	if c.GRPCMaxRecvMsgSize <= 0 || c.GRPCMaxSendMsgSize <= 0 {
+		add("grpc-max-recv-msg-size and grpc-max-send-msg-size must be positive, got %d and %d", c.GRPCMaxRecvMsgSize, c.GRPCMaxSendMsgSize)
+	}
+	if c.GRPCMaxStreams > math.MaxUint32 {
+		add("grpc-max-concurrent-streams must not exceed %d, got %d", uint32(math.MaxUint32), c.GRPCMaxStreams)
+	}
+	switch c.TraceExporter {
+	case "", "otlp", "stdout":
+	default:
+		add("trace-exporter must be otlp, stdout or empty, got '%s'", c.TraceExporter)
+	}
+	if c.TraceSampleRatio < 0 || c.TraceSampleRatio > 1 {
+		add("trace-sample-ratio must be between 0 and 1, got %v", c.TraceSampleRatio)
+	}
+	if _, _, err := c.rateLimits(); err != nil {
+		add("%v", err)
+	}
+	if _, err := middleware.ParseProxies(c.TrustedProxies); err != nil {
+		add("trusted-proxies: %v", err)
+	}
+	if _, err := c.timeouts(); err != nil {
+		add("%v", err)
+	}
+	if _, err := cors.ParseOrigins(c.GRPCWebOrigins); err != nil {
+		add("grpc-web-allowed-origins: %v", err)
+	}
+	if _, err := c.cors(); err != nil {
+		add("%v", err)
+	}
+	if len(c.AdminPort) > 0 && len(c.AdminToken) == 0 && !admin.IsLoopback(c.AdminBind) {
+		add("admin-token is required unless admin-bind is a loopback address such as 127.0.0.1")
+	}
+
+	if len(errs) > 0 {
+		return errors.New("[ERROR] Invalid configuration:\n  - " + strings.Join(errs, "\n  - "))
+	}
+	return nil
+}
+
+// rateLimits returns the default and per-method rate limits; zero limits are unlimited.
+func (c *Config) rateLimits() (middleware.RateLimit, map[string]middleware.RateLimit, error) {
+	var def middleware.RateLimit
+	if len(c.RateLimit) > 0 {
+		l, err := middleware.ParseRateLimit(c.RateLimit)
+		if err != nil {
+			return def, nil, fmt.Errorf("rate-limit: %v", err)
+		}
+		def = l
+	}
+	methods, err := middleware.ParseMethodLimits(c.MethodRateLimits)
+	if err != nil {
+		return def, nil, fmt.Errorf("method-rate-limits: %v", err)
+	}
+	return def, methods, nil
+}
+
+func (c *Config) timeouts() (middleware.Timeouts, error) {
+	if c.DefaultTimeout < 0 || c.MaxTimeout < 0 || (c.MaxTimeout > 0 && c.DefaultTimeout > c.MaxTimeout) {
+		return middleware.Timeouts{}, fmt.Errorf("default-timeout '%v' must not exceed max-timeout '%v'", c.DefaultTimeout, c.MaxTimeout)
+	}
+	methods, err := middleware.ParseMethodTimeouts(c.MethodTimeouts)
+	if err != nil {
+		return middleware.Timeouts{}, fmt.Errorf("method-timeouts: %v", err)
+	}
+	return middleware.Timeouts{
+		Server:  middleware.Timeout{Default: c.DefaultTimeout, Max: c.MaxTimeout},
+		Methods: methods,
+	}, nil
+}
+
+// cors returns the gateway CORS configuration; CORS is disabled without allowed origins.
+func (c *Config) cors() (restmiddleware.CORS, error) {
+	if len(c.CORSOrigins) == 0 {
+		return restmiddleware.CORS{}, nil
+	}
+	origins, err := cors.ParseOrigins(c.CORSOrigins)
+	if err != nil {
+		return restmiddleware.CORS{}, fmt.Errorf("cors-allowed-origins: %v", err)
+	}
+	// Browsers refuse credentialed responses allowing any origin, and echoing the
+	// origin instead would let every site make credentialed calls.
+	if origins.Any() && c.CORSCredentials {
+		return restmiddleware.CORS{}, fmt.Errorf("cors-allowed-origins '*' cannot be combined with cors-allow-credentials")
+	}
+	return restmiddleware.CORS{
+		Origins:          origins,
+		Methods:          splitList(c.CORSMethods),
+		Headers:          splitList(c.CORSHeaders),
+		AllowCredentials: c.CORSCredentials,
+		MaxAge:           c.CORSMaxAge,
+	}, nil
+}
+
+func (c *Config) logging() (logger.Config, error) {
+	switch c.LogFormat {
+	case logger.FormatJSON, logger.FormatConsole:
+	default:
+		return logger.Config{}, fmt.Errorf("log-format must be json or console, got '%s'", c.LogFormat)
+	}
+	if c.LogMaxSizeMB <= 0 || c.LogMaxAgeDays < 0 || c.LogMaxBackups < 0 || c.LogRotateInterval < 0 {
+		return logger.Config{}, fmt.Errorf("log-max-size-mb must be positive and log-max-age-days, log-max-backups and log-rotate-interval must not be negative")
+	}
+	if c.LogSampleInitial < 0 || c.LogSampleThereafter <= 0 {
+		return logger.Config{}, fmt.Errorf("log-sample-initial must not be negative and log-sample-thereafter must be positive, got %d and %d", c.LogSampleInitial, c.LogSampleThereafter)
+	}
+	levels, err := logger.ParsePackageLevels(c.LogPackageLevels)
+	if err != nil {
+		return logger.Config{}, fmt.Errorf("log-package-levels: %v", err)
+	}
+	return logger.Config{
+		Level:            c.LogLevel,
+		PackageLevels:    levels,
+		Format:           c.LogFormat,
+		File:             c.LogFile,
+		MaxSizeMB:        c.LogMaxSizeMB,
+		MaxAgeDays:       c.LogMaxAgeDays,
+		MaxBackups:       c.LogMaxBackups,
+		Compress:         c.LogCompress,
+		RotateInterval:   c.LogRotateInterval,
+		SampleInitial:    c.LogSampleInitial,
+		SampleThereafter: c.LogSampleThereafter,
+	}, nil
+}
+
+func (c *Config) payloads() payload.Options {
+	return payload.Options{
+		Enabled:     c.LogPayloads,
+		RedactPaths: splitList(c.LogPayloadRedact),
+		MaxBytes:    c.LogPayloadMaxBytes,
+		SampleRate:  c.LogPayloadSampleRate,
+	}
+}
+
+// splitList splits a comma-separated setting, dropping empty entries.
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); len(v) > 0 {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// redact hides the value of secret settings.
+func redact(name, value string) string {
+	if secretSettings[name] && len(value) > 0 {
+		return redacted
+	}
+	return value
+}
+
+// PrintConfig writes the effective configuration for args as YAML that can be fed back
+// through -config, with secrets redacted.
+func PrintConfig(w io.Writer, args []string, lookupEnv func(string) (string, bool)) error {
+	cfg, fs, err := loadConfig(args, lookupEnv)
+	if err != nil {
+		return err
+	}
+
+	var out yaml.MapSlice
+	fs.VisitAll(func(f *flag.Flag) {
+		// A password read from db-password-file stays out, so the output loads back.
+		if f.Name == "config" || (f.Name == "db-password" && len(cfg.DatastoreDBPasswordFile) > 0) {
+			return
+		}
+		out = append(out, yaml.MapItem{Key: f.Name, Value: redact(f.Name, f.Value.String())})
+	})
+	b, err := yaml.Marshal(out)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
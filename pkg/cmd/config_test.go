@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(k string) (string, bool) {
+		v, ok := vars[k]
+		return v, ok
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	yamlFile := writeFile(t, "config.yaml", `
+grpc-port: 9090
+http-port: 8080
+log-level: 1
+db:
+  host: file-host:3306
+  user: file-user
+cors-allowed-origins:
+  - https://a.example.com
+  - https://*.example.org
+`)
+	tomlFile := writeFile(t, "config.toml", `
+grpc-port = "9090"
+http-port = "8080"
+
+[db]
+host = "toml-host:3306"
+`)
+	passwordFile := writeFile(t, "password", "s3cret\n")
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		check   func(t *testing.T, c *Config)
+		wantErr string
+	}{
+		{
+			name: "01 - Flags override env override file override defaults",
+			args: []string{"-config", yamlFile, "-db-user", "flag-user"},
+			env:  map[string]string{"FOO_DB_USER": "env-user", "FOO_DB_HOST": "env-host:3306"},
+			check: func(t *testing.T, c *Config) {
+				if c.DatastoreDBUser != "flag-user" || c.DatastoreDBHost != "env-host:3306" || c.LogLevel != 1 || c.GRPCPort != "9090" {
+					t.Errorf("unexpected config %+v", c)
+				}
+				if c.CORSOrigins != "https://a.example.com,https://*.example.org" {
+					t.Errorf("CORSOrigins = %q", c.CORSOrigins)
+				}
+				if c.CORSMethods != "GET,POST,PUT,PATCH,DELETE" {
+					t.Errorf("default CORSMethods lost: %q", c.CORSMethods)
+				}
+			},
+		},
+		{
+			name: "02 - TOML file from FOO_CONFIG",
+			env:  map[string]string{"FOO_CONFIG": tomlFile},
+			check: func(t *testing.T, c *Config) {
+				if c.DatastoreDBHost != "toml-host:3306" || c.HTTPPort != "8080" {
+					t.Errorf("unexpected config %+v", c)
+				}
+			},
+		},
+		{
+			name: "03 - Password file",
+			args: []string{"-port", "8080", "-db-password-file", passwordFile},
+			check: func(t *testing.T, c *Config) {
+				if c.DatastoreDBPassword != "s3cret" {
+					t.Errorf("DatastoreDBPassword = %q", c.DatastoreDBPassword)
+				}
+			},
+		},
+		{
+			name:    "04 - Password and password file",
+			args:    []string{"-port", "8080", "-db-password-file", passwordFile},
+			env:     map[string]string{"FOO_DB_PASSWORD": "other"},
+			wantErr: "only one of db-password and db-password-file",
+		},
+		{
+			name:    "05 - Unknown file setting",
+			args:    []string{"-config", writeFile(t, "typo.yaml", "grpc-prot: 9090\n")},
+			wantErr: "unknown setting 'grpc-prot', did you mean 'grpc-port'?",
+		},
+		{
+			name:    "06 - Invalid env value",
+			args:    []string{"-port", "8080"},
+			env:     map[string]string{"FOO_LOG_LEVEL": "debug"},
+			wantErr: "FOO_LOG_LEVEL",
+		},
+		{
+			name:    "07 - All validation errors reported",
+			args:    []string{"-trace-sample-ratio", "2", "-rate-limit", "x"},
+			wantErr: "grpc-port is required unless port is set\n  - http-port is required unless port is set\n  - trace-sample-ratio",
+		},
+		{
+			name:    "08 - Invalid package log level",
+			args:    []string{"-port", "8080", "-log-package-levels", "grpc=loud"},
+			wantErr: "log-package-levels: invalid level 'loud' for package 'grpc'",
+		},
+		{
+			name:    "09 - Any CORS origin with credentials",
+			args:    []string{"-port", "8080", "-cors-allowed-origins", "*", "-cors-allow-credentials"},
+			wantErr: "cors-allowed-origins '*' cannot be combined with cors-allow-credentials",
+		},
+		{
+			name:    "10 - Admin port without token off loopback",
+			args:    []string{"-port", "8080", "-admin-port", "9102"},
+			wantErr: "admin-token is required unless admin-bind is a loopback address",
+		},
+		{
+			name:    "11 - Invalid trusted proxy",
+			args:    []string{"-port", "8080", "-trusted-proxies", "10.0.0.0/8,lb.internal"},
+			wantErr: "trusted-proxies: invalid proxy address 'lb.internal'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := LoadConfig(tt.args, env(tt.env))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			tt.check(t, c)
+		})
+	}
+}
+
+func TestPrintConfig(t *testing.T) {
+	var out bytes.Buffer
+	err := PrintConfig(&out, []string{"-port", "8080"}, env(map[string]string{"FOO_DB_PASSWORD": "s3cret"}))
+	if err != nil {
+		t.Fatalf("PrintConfig() error = %v", err)
+	}
+	if strings.Contains(out.String(), "s3cret") || !strings.Contains(out.String(), "db-password: <redacted>") {
+		t.Errorf("password not redacted:\n%s", out.String())
+	}
+
+	// The output is a valid config file.
+	if _, err := LoadConfig([]string{"-config", writeFile(t, "printed.yaml", out.String())}, env(nil)); err != nil {
+		t.Errorf("printed config does not load back: %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/logger"
+)
+
+// Server is a long-running component managed by Lifecycle.
+// Serve blocks until the server stops and returns nil after a graceful Shutdown.
+type Server interface {
+	Serve() error
+	Shutdown(ctx context.Context) error
+}
+
+type namedServer struct {
+	name   string
+	server Server
+}
+
+type namedCloser struct {
+	name  string
+	close func() error
+}
+
+// Lifecycle starts servers, waits for a termination signal or the first fatal
+// error, then drains the servers in the order they were added and runs the
+// closers last, in reverse order, all within the shutdown timeout.
+type Lifecycle struct {
+	servers         []namedServer
+	closers         []namedCloser
+	shutdownTimeout time.Duration
+	// drainDelay is waited after a termination signal, with Draining set, before the
+	// servers are drained, so load balancers see readiness fail while they still serve.
+	drainDelay time.Duration
+	draining   int32
+	signals    []os.Signal
+}
+
+func NewLifecycle(shutdownTimeout time.Duration) *Lifecycle {
+	return &Lifecycle{
+		shutdownTimeout: shutdownTimeout,
+		signals:         []os.Signal{os.Interrupt, syscall.SIGTERM},
+	}
+}
+
+// AddServer registers a server. Servers are shut down in registration order.
+func (l *Lifecycle) AddServer(name string, s Server) {
+	l.servers = append(l.servers, namedServer{name: name, server: s})
+}
+
+// AddCloser registers a resource released after every server has stopped. Closers run in
+// reverse registration order, like deferred calls, so a resource outlives those added after it.
+func (l *Lifecycle) AddCloser(name string, close func() error) {
+	l.closers = append(l.closers, namedCloser{name: name, close: close})
+}
+
+// Draining reports whether shutdown has started.
+func (l *Lifecycle) Draining() bool {
+	return atomic.LoadInt32(&l.draining) == 1
+}
+
+// Run blocks until ctx is cancelled, a termination signal is received or a server
+// fails. It returns the first fatal server error, or nil on a clean shutdown.
+func (l *Lifecycle) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, l.signals...)
+	defer stop()
+
+	errCh := make(chan error, len(l.servers))
+	for _, s := range l.servers {
+		go func(s namedServer) {
+			if err := s.server.Serve(); err != nil {
+				errCh <- fmt.Errorf("[ERROR] %s server failed: %w", s.name, err)
+				return
+			}
+			errCh <- nil
+		}(s)
+	}
+
+	var runErr error
+	stopped := 0
+	select {
+	case <-ctx.Done():
+		logger.Log.Warn("Received shutdown signal")
+	case err := <-errCh:
+		stopped++
+		runErr = err
+		if err == nil {
+			runErr = fmt.Errorf("[ERROR] server stopped unexpectedly")
+		}
+		logger.Log.Error("Server stopped, shutting down", zap.Error(runErr))
+	}
+
+	atomic.StoreInt32(&l.draining, 1)
+	if runErr == nil && l.drainDelay > 0 {
+		logger.Log.Info("Waiting for load balancers to stop routing traffic", zap.Duration("delay", l.drainDelay))
+		time.Sleep(l.drainDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), l.shutdownTimeout)
+	defer cancel()
+
+	for _, s := range l.servers {
+		logger.Log.Info("Draining server", zap.String("server", s.name))
+		if err := s.server.Shutdown(shutdownCtx); err != nil {
+			logger.Log.Error("Failed to shut down server gracefully", zap.String("server", s.name), zap.Error(err))
+		}
+	}
+
+wait:
+	for ; stopped < len(l.servers); stopped++ {
+		select {
+		case err := <-errCh:
+			if runErr == nil && err != nil {
+				runErr = err
+			}
+		case <-shutdownCtx.Done():
+			logger.Log.Error("Timed out waiting for servers to stop")
+			break wait
+		}
+	}
+
+	l.Close()
+	return runErr
+}
+
+// Close runs the closers without serving. Run calls it once the servers have stopped;
+// callers use it directly when startup fails before Run.
+func (l *Lifecycle) Close() {
+	for i := len(l.closers) - 1; i >= 0; i-- {
+		c := l.closers[i]
+		if err := c.close(); err != nil {
+			logger.Log.Error("Failed to close resource", zap.String("resource", c.name), zap.Error(err))
+		}
+	}
+}
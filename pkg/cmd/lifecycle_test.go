@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(e string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func (r *recorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+// fakeServer blocks in Serve until shut down, or fails with serveErr.
+type fakeServer struct {
+	name     string
+	rec      *recorder
+	serveErr error
+	hang     bool
+	stop     chan struct{}
+	once     sync.Once
+}
+
+func newFakeServer(name string, rec *recorder) *fakeServer {
+	return &fakeServer{name: name, rec: rec, stop: make(chan struct{})}
+}
+
+func (s *fakeServer) Serve() error {
+	if s.serveErr != nil {
+		return s.serveErr
+	}
+	<-s.stop
+	return nil
+}
+
+func (s *fakeServer) Shutdown(ctx context.Context) error {
+	s.rec.add("shutdown " + s.name)
+	if s.hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	s.once.Do(func() { close(s.stop) })
+	return nil
+}
+
+func TestLifecycle_Run(t *testing.T) {
+	fatal := errors.New("listen failed")
+
+	tests := []struct {
+		name    string
+		setup   func(rec *recorder) (*Lifecycle, context.Context, context.CancelFunc)
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "01 - Cancelled context drains HTTP, then gRPC, then closes DB",
+			setup: func(rec *recorder) (*Lifecycle, context.Context, context.CancelFunc) {
+				lc := NewLifecycle(time.Second)
+				lc.AddServer("http", newFakeServer("http", rec))
+				lc.AddServer("grpc", newFakeServer("grpc", rec))
+				lc.AddCloser("db", func() error { rec.add("close db"); return nil })
+				ctx, cancel := context.WithCancel(context.Background())
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					cancel()
+				}()
+				return lc, ctx, cancel
+			},
+			want: []string{"shutdown http", "shutdown grpc", "close db"},
+		},
+		{
+			name: "02 - Fatal server error is propagated",
+			setup: func(rec *recorder) (*Lifecycle, context.Context, context.CancelFunc) {
+				lc := NewLifecycle(time.Second)
+				lc.AddServer("http", &fakeServer{name: "http", rec: rec, serveErr: fatal, stop: make(chan struct{})})
+				lc.AddServer("grpc", newFakeServer("grpc", rec))
+				lc.AddCloser("db", func() error { rec.add("close db"); return nil })
+				ctx, cancel := context.WithCancel(context.Background())
+				return lc, ctx, cancel
+			},
+			want:    []string{"shutdown http", "shutdown grpc", "close db"},
+			wantErr: fatal,
+		},
+		{
+			name: "03 - Shutdown deadline is enforced",
+			setup: func(rec *recorder) (*Lifecycle, context.Context, context.CancelFunc) {
+				lc := NewLifecycle(20 * time.Millisecond)
+				s := newFakeServer("http", rec)
+				s.hang = true
+				lc.AddServer("http", s)
+				lc.AddCloser("db", func() error { rec.add("close db"); return nil })
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return lc, ctx, cancel
+			},
+			want: []string{"shutdown http", "close db"},
+		},
+		{
+			name: "04 - Database closes after the gateway connection",
+			setup: func(rec *recorder) (*Lifecycle, context.Context, context.CancelFunc) {
+				lc := NewLifecycle(time.Second)
+				lc.AddServer("http", newFakeServer("http", rec))
+				lc.AddCloser("db", func() error { rec.add("close db"); return nil })
+				lc.AddCloser("gateway connection", func() error { rec.add("close gateway connection"); return nil })
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return lc, ctx, cancel
+			},
+			want: []string{"shutdown http", "close gateway connection", "close db"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			lc, ctx, cancel := tt.setup(rec)
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() { done <- lc.Run(ctx) }()
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Lifecycle.Run() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Lifecycle.Run() did not return")
+			}
+
+			if got := rec.get(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lifecycle.Run() events = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLifecycle_Close(t *testing.T) {
+	rec := &recorder{}
+	lc := NewLifecycle(time.Second)
+	lc.AddServer("http", newFakeServer("http", rec))
+	lc.AddCloser("tracing", func() error { rec.add("close tracing"); return nil })
+	lc.AddCloser("db", func() error { rec.add("close db"); return errors.New("already closed") })
+	lc.AddCloser("gateway connection", func() error { rec.add("close gateway connection"); return nil })
+
+	lc.Close()
+
+	// Reverse order: the database outlives the gateway connection, tracing outlives both.
+	want := []string{"close gateway connection", "close db", "close tracing"}
+	if got := rec.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lifecycle.Close() events = %v, want %v", got, want)
+	}
+}
+
+// drainingServer records whether its Lifecycle was draining when shut down.
+type drainingServer struct {
+	*fakeServer
+	lc *Lifecycle
+}
+
+func (s *drainingServer) Shutdown(ctx context.Context) error {
+	if s.lc.Draining() {
+		s.rec.add("draining")
+	}
+	return s.fakeServer.Shutdown(ctx)
+}
+
+func TestLifecycle_Draining(t *testing.T) {
+	rec := &recorder{}
+	lc := NewLifecycle(time.Second)
+	lc.drainDelay = 10 * time.Millisecond
+	lc.AddServer("http", &drainingServer{fakeServer: newFakeServer("http", rec), lc: lc})
+	if lc.Draining() {
+		t.Fatal("Draining() before shutdown = true")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := lc.Run(ctx); err != nil {
+		t.Fatalf("Lifecycle.Run() error = %v", err)
+	}
+
+	want := []string{"draining", "shutdown http"}
+	if got := rec.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lifecycle.Run() events = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"flag"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/logger"
+)
+
+// mutableSettings are applied by a reload without a restart. Changes to any other
+// setting are logged and ignored until the server restarts.
+var mutableSettings = map[string]bool{
+	"log-level":                true,
+	"log-package-levels":       true,
+	"log-payloads":             true,
+	"log-payload-redact":       true,
+	"log-payload-max-bytes":    true,
+	"log-payload-sample-rate":  true,
+	"rate-limit":               true,
+	"method-rate-limits":       true,
+	"rate-limit-api-keys":      true,
+	"default-timeout":          true,
+	"max-timeout":              true,
+	"method-timeouts":          true,
+	"grpc-web-allowed-origins": true,
+	"cors-allowed-origins":     true,
+	"cors-allowed-methods":     true,
+	"cors-allowed-headers":     true,
+	"cors-allow-credentials":   true,
+	"cors-max-age":             true,
+}
+
+func settings(fs *flag.FlagSet) map[string]string {
+	m := map[string]string{}
+	fs.VisitAll(func(f *flag.Flag) {
+		m[f.Name] = f.Value.String()
+	})
+	return m
+}
+
+// reloader re-reads the configuration on SIGHUP and whenever the config file changes,
+// then passes it to apply. It runs as a Lifecycle server.
+type reloader struct {
+	args      []string
+	lookupEnv func(string) (string, bool)
+	file      string
+	interval  time.Duration
+	apply     func(*Config)
+
+	mu       sync.Mutex
+	current  map[string]string
+	fileHash []byte
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// newReloader watches the configuration loaded from args and lookupEnv, currently applied as cfg.
+func newReloader(args []string, lookupEnv func(string) (string, bool), cfg *Config, fs *flag.FlagSet, apply func(*Config)) *reloader {
+	r := &reloader{
+		args:      args,
+		lookupEnv: lookupEnv,
+		file:      cfg.ConfigFile,
+		interval:  cfg.ConfigReloadInterval,
+		apply:     apply,
+		current:   settings(fs),
+		stop:      make(chan struct{}),
+	}
+	r.fileHash = r.hashFile()
+	return r
+}
+
+// Settings returns the settings in effect, with secrets redacted.
+func (r *reloader) Settings() map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := make(map[string]string, len(r.current))
+	for name, v := range r.current {
+		m[name] = redact(name, v)
+	}
+	return m
+}
+
+func (r *reloader) hashFile() []byte {
+	b, err := ioutil.ReadFile(r.file)
+	if err != nil {
+		return nil
+	}
+	sum := sha256.Sum256(b)
+	return sum[:]
+}
+
+func (r *reloader) Serve() error {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if len(r.file) > 0 && r.interval > 0 {
+		t := time.NewTicker(r.interval)
+		defer t.Stop()
+		tick = t.C
+	}
+
+	for {
+		select {
+		case <-r.stop:
+			return nil
+		case <-hup:
+			r.fileHash = r.hashFile()
+			r.reload("SIGHUP")
+		case <-tick:
+			if h := r.hashFile(); h != nil && !bytes.Equal(h, r.fileHash) {
+				r.fileHash = h
+				r.reload("config file changed")
+			}
+		}
+	}
+}
+
+func (r *reloader) Shutdown(ctx context.Context) error {
+	r.once.Do(func() { close(r.stop) })
+	return nil
+}
+
+// reload applies the mutable settings of the new configuration. An invalid
+// configuration is rejected as a whole and the running one is kept.
+func (r *reloader) reload(trigger string) {
+	cfg, fs, err := loadConfig(r.args, r.lookupEnv)
+	if err != nil {
+		logger.Log.Error("Failed to reload configuration, keeping the running one", zap.String("trigger", trigger), zap.Error(err))
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next := settings(fs)
+	var changed, ignored []string
+	for name, v := range next {
+		if r.current[name] == v {
+			continue
+		}
+		if mutableSettings[name] {
+			changed = append(changed, name)
+		} else {
+			ignored = append(ignored, name)
+			next[name] = r.current[name]
+		}
+	}
+	sort.Strings(changed)
+	sort.Strings(ignored)
+
+	if len(ignored) > 0 {
+		logger.Log.Warn("Ignoring changed settings that require a restart", zap.String("trigger", trigger), zap.Strings("settings", ignored))
+	}
+	if len(changed) == 0 {
+		logger.Log.Info("Configuration reloaded, no live settings changed", zap.String("trigger", trigger))
+		return
+	}
+
+	r.apply(cfg)
+	r.current = next
+	logger.Log.Info("Configuration reloaded", zap.String("trigger", trigger), zap.Strings("changed", changed))
+}
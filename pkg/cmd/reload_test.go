@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestReloader_reload(t *testing.T) {
+	path := writeFile(t, "config.yaml", "port: 8080\nlog-level: 0\n")
+	args := []string{"-config", path}
+	cfg, fs, err := loadConfig(args, env(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var applied []*Config
+	r := newReloader(args, env(nil), cfg, fs, func(c *Config) { applied = append(applied, c) })
+
+	write := func(content string) {
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Mutable change is applied.
+	write("port: 8080\nlog-level: 1\nrate-limit: 5\n")
+	r.reload("test")
+	if len(applied) != 1 || applied[0].LogLevel != 1 || applied[0].RateLimit != "5" {
+		t.Fatalf("mutable change not applied: %+v", applied)
+	}
+
+	// Only immutable change: nothing is applied.
+	write("port: 9090\nlog-level: 1\nrate-limit: 5\n")
+	r.reload("test")
+	if len(applied) != 1 {
+		t.Fatalf("immutable change applied: %+v", applied[len(applied)-1])
+	}
+	if r.current["port"] != "8080" {
+		t.Errorf("running port = %s, want 8080", r.current["port"])
+	}
+
+	// Invalid configuration is rejected.
+	write("port: 8080\nlog-level: 9\n")
+	r.reload("test")
+	if len(applied) != 1 || r.current["log-level"] != "1" {
+		t.Fatalf("invalid configuration applied")
+	}
+}
@@ -3,50 +3,99 @@ package cmd
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"flag"
 	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/audit"
 	"github.com/wingkwong/go-grpc-boilerplate/pkg/logger"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/metrics"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/admin"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/cors"
 	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/grpc"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/grpc/middleware"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/multiplex"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/payload"
 	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/rest"
+	restmiddleware "github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/rest/middleware"
 	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/service/v1"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/tracing"
 )
 
-type Config struct {
-	GRPCPort            string
-	HTTPPort            string
-	DatastoreDBHost     string
-	DatastoreDBUser     string
-	DatastoreDBPassword string
-	DatastoreDBSchema   string
-	LogLevel            int
-}
-
-func RunServer() error {
+// RunServer loads the configuration from args, the environment and the -config file,
+// then serves until a termination signal is received.
+func RunServer(args []string) error {
 	ctx := context.Background()
 
-	var cfg Config
-	flag.StringVar(&cfg.GRPCPort, "grpc-port", "", "gRPC port to bind")
-	flag.StringVar(&cfg.HTTPPort, "http-port", "", "HTTP port to bind")
-	flag.StringVar(&cfg.DatastoreDBHost, "db-host", "", "Database host")
-	flag.StringVar(&cfg.DatastoreDBUser, "db-user", "", "Database user")
-	flag.StringVar(&cfg.DatastoreDBPassword, "db-password", "", "Database password")
-	flag.StringVar(&cfg.DatastoreDBSchema, "db-schema", "", "Database schema")
-	flag.IntVar(&cfg.LogLevel, "log-level", 0, "Global log level: Debug(-1), Info(0), Warn(1), Error(2), DPanic(3), Panic(4), Fatal(5)")
-	flag.Parse()
-
-	if len(cfg.GRPCPort) == 0 {
-		return fmt.Errorf("[ERROR] Invalid TCP port for gRPC server: '%s'", cfg.GRPCPort)
+	cfg, fs, err := loadConfig(args, os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return err
 	}
 
-	if len(cfg.HTTPPort) == 0 {
-		return fmt.Errorf("[ERROR] Invalid TCP port for HTTP gateway: '%s'", cfg.HTTPPort)
+	// The settings below can be changed by a reload; they were validated by loadConfig.
+	defLimit, methodLimits, _ := cfg.rateLimits()
+	limiter := middleware.NewRateLimiter(defLimit, methodLimits)
+	limiter.SetAPIKeys(splitList(cfg.RateLimitAPIKeys))
+	proxies, _ := middleware.ParseProxies(cfg.TrustedProxies)
+	limiter.SetTrustedProxies(proxies)
+	t, _ := cfg.timeouts()
+	timeouts := middleware.NewReloadableTimeouts(t)
+	c, _ := cfg.cors()
+	corsPolicy := restmiddleware.NewReloadableCORS(c)
+	payloads := payload.NewReloadablePolicy(cfg.payloads())
+	var grpcWebOrigins atomic.Value
+	o, _ := cors.ParseOrigins(cfg.GRPCWebOrigins)
+	grpcWebOrigins.Store(o)
+
+	apply := func(cfg *Config) {
+		logger.SetLevel(cfg.LogLevel)
+		l, _ := cfg.logging()
+		logger.SetPackageLevels(l.PackageLevels)
+		defLimit, methodLimits, _ := cfg.rateLimits()
+		limiter.Update(defLimit, methodLimits)
+		limiter.SetAPIKeys(splitList(cfg.RateLimitAPIKeys))
+		t, _ := cfg.timeouts()
+		timeouts.Store(t)
+		c, _ := cfg.cors()
+		corsPolicy.Store(c)
+		payloads.Store(cfg.payloads())
+		o, _ := cors.ParseOrigins(cfg.GRPCWebOrigins)
+		grpcWebOrigins.Store(o)
 	}
 
-	if err := logger.Init(cfg.LogLevel); err != nil {
+	logConfig, _ := cfg.logging()
+	closeLogger, err := logger.Init(logConfig)
+	if err != nil {
 		return fmt.Errorf("[ERROR] Failed to initialize logger: %v", err)
 	}
+	defer func() { _ = closeLogger() }()
+
+	shutdownTracing, err := tracing.Init(ctx, tracing.Config{
+		Exporter:     cfg.TraceExporter,
+		ServiceName:  "foo-service",
+		OTLPEndpoint: cfg.TraceOTLPEndpoint,
+		OTLPInsecure: cfg.TraceOTLPInsecure,
+		File:         cfg.TraceFile,
+		SampleRatio:  cfg.TraceSampleRatio,
+	})
+	if err != nil {
+		return fmt.Errorf("[ERROR] Failed to initialize tracing: %v", err)
+	}
+
+	lc := NewLifecycle(cfg.ShutdownTimeout)
+	lc.drainDelay = cfg.ShutdownDelay
+	lc.AddCloser("tracing", func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return shutdownTracing(ctx)
+	})
 
 	param := "parseTime=true"
 
@@ -60,16 +109,82 @@ func RunServer() error {
 	)
 	db, err := sql.Open("mysql", dsn)
 	if err != nil {
+		lc.Close()
 		return fmt.Errorf("[ERROR] Failed to open database: %v", err)
 	}
+	lc.AddCloser("database", db.Close)
 
-	defer db.Close()
+	if err := metrics.RegisterDB(db, cfg.DatastoreDBSchema); err != nil {
+		lc.Close()
+		return fmt.Errorf("[ERROR] Failed to register database metrics: %v", err)
+	}
 
 	v1API := v1.NewFooServiceServer(db)
+	auditStore := audit.NewStore(db)
+	var auditRecorder audit.Recorder
+	if cfg.Audit {
+		auditRecorder = auditStore
+	}
 
-	go func() {
-		_ = rest.RunServer(ctx, cfg.GRPCPort, cfg.HTTPPort)
-	}()
+	grpcServer, err := grpc.NewServer(v1API, cfg.GRPCPort, grpc.Options{
+		RateLimiter:                  limiter,
+		Timeouts:                     timeouts,
+		Payloads:                     payloads,
+		Audit:                        auditRecorder,
+		AuditAPI:                     v1.NewAuditServiceServer(auditStore, cfg.AdminToken),
+		MaxRecvMsgSize:               cfg.GRPCMaxRecvMsgSize,
+		MaxSendMsgSize:               cfg.GRPCMaxSendMsgSize,
+		MaxConcurrentStreams:         uint32(cfg.GRPCMaxStreams),
+		KeepaliveMinTime:             cfg.GRPCKeepaliveMin,
+		KeepalivePermitWithoutStream: cfg.GRPCKeepaliveIdle,
+	})
+	if err != nil {
+		lc.Close()
+		return fmt.Errorf("[ERROR] Failed to start gRPC server: %v", err)
+	}
+
+	conn, err := grpcServer.DialInProcess(ctx, rest.DialOptions()...)
+	if err != nil {
+		lc.Close()
+		return fmt.Errorf("[ERROR] Failed to dial gRPC server: %v", err)
+	}
+	lc.AddCloser("gateway connection", conn.Close)
+
+	handler, err := rest.NewHandler(ctx, conn, db.PingContext, rest.Options{
+		CORS:           corsPolicy,
+		DocsScriptURL:  cfg.DocsScriptURL,
+		Payloads:       payloads,
+		TrustedProxies: proxies,
+		Draining:       lc.Draining,
+	})
+	if err != nil {
+		lc.Close()
+		return fmt.Errorf("[ERROR] Failed to start HTTP gateway: %v", err)
+	}
+
+	grpcWeb := grpcServer.GRPCWeb(func(origin string) bool {
+		return grpcWebOrigins.Load().(*cors.Origins).Allowed(origin)
+	})
+	var restServer *rest.Server
+	if len(cfg.Port) > 0 {
+		restServer = rest.NewServer(cfg.Port, multiplex.NewHandler(grpcServer, grpcWeb, handler, uint32(cfg.GRPCMaxStreams)))
+	} else {
+		restServer = rest.NewServer(cfg.HTTPPort, multiplex.NewHandler(nil, grpcWeb, handler, uint32(cfg.GRPCMaxStreams)))
+	}
+
+	// HTTP is drained first so in-flight gateway calls can still reach gRPC.
+	lc.AddServer("HTTP/REST gateway", restServer)
+	lc.AddServer("gRPC", grpcServer)
+	reloader := newReloader(args, os.LookupEnv, cfg, fs, apply)
+	lc.AddServer("config reloader", reloader)
+	if len(cfg.AdminPort) > 0 {
+		lc.AddServer("admin", admin.NewServer(cfg.AdminPort, admin.Options{
+			Bind:   cfg.AdminBind,
+			Token:  cfg.AdminToken,
+			Audit:  auditRecorder,
+			Config: reloader.Settings,
+		}))
+	}
 
-	return grpc.RunServer(ctx, v1API, cfg.GRPCPort)
+	return lc.Run(ctx)
 }
@@ -0,0 +1,111 @@
+package fooctl
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/buildinfo"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/client"
+)
+
+// backend calls the Foo service over one of the transports. Its methods return *client.Error
+// for failed calls.
+type backend interface {
+	Create(ctx context.Context, foo *v1.Foo) (int64, error)
+	Get(ctx context.Context, id int64) (*v1.Foo, error)
+	Update(ctx context.Context, foo *v1.Foo) error
+	Delete(ctx context.Context, id int64) error
+	// List calls fn with every Foo in ID order, fetching pageSize Foos per call.
+	List(ctx context.Context, pageSize int32, fn func(*v1.Foo) error) error
+	Close() error
+}
+
+func tlsConfig(p Profile) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if p.CACert != "" {
+		pem, err := ioutil.ReadFile(p.CACert)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in '%s'", p.CACert)
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+func userAgent() string {
+	return "fooctl/" + buildinfo.Get().Version
+}
+
+// connect returns the backend for the transport of p; extra options apply to gRPC only.
+func connect(p Profile, extra ...client.Option) (backend, error) {
+	config, err := tlsConfig(p)
+	if err != nil {
+		return nil, err
+	}
+	switch p.Transport {
+	case TransportGRPC:
+		opts := []client.Option{
+			client.WithTimeout(p.Timeout),
+			client.WithToken(p.Token),
+			client.WithAPIKey(p.APIKey),
+			client.WithTenant(p.Tenant),
+			client.WithUserAgent(userAgent()),
+		}
+		opts = append(opts, extra...)
+		if p.Insecure {
+			opts = append(opts, client.WithInsecure())
+		} else {
+			opts = append(opts, client.WithTLS(config))
+		}
+		c, err := client.New(p.Server, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return grpcBackend{c}, nil
+	case TransportREST:
+		return newRESTBackend(p, config)
+	}
+	return nil, fmt.Errorf("invalid transport '%s', want %s or %s", p.Transport, TransportGRPC, TransportREST)
+}
+
+type grpcBackend struct {
+	c *client.Client
+}
+
+func (b grpcBackend) Create(ctx context.Context, foo *v1.Foo) (int64, error) {
+	return b.c.Foos().Create(ctx, foo)
+}
+
+func (b grpcBackend) Get(ctx context.Context, id int64) (*v1.Foo, error) {
+	return b.c.Foos().Get(ctx, id)
+}
+
+func (b grpcBackend) Update(ctx context.Context, foo *v1.Foo) error {
+	return b.c.Foos().Update(ctx, foo)
+}
+
+func (b grpcBackend) Delete(ctx context.Context, id int64) error {
+	return b.c.Foos().Delete(ctx, id)
+}
+
+func (b grpcBackend) List(ctx context.Context, pageSize int32, fn func(*v1.Foo) error) error {
+	it := b.c.Foos().List(ctx, client.PageSize(pageSize))
+	for it.Next() {
+		if err := fn(it.Foo()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+func (b grpcBackend) Close() error {
+	return b.c.Close()
+}
@@ -0,0 +1,473 @@
+package fooctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+)
+
+const (
+	defaultListPageSize = 100
+	// maxCompletedIDs bounds the IDs listed for shell completion.
+	maxCompletedIDs = 200
+)
+
+func newFooCommand(g *globals) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "foo",
+		Short: "Create, read, update, delete and transfer Foos",
+	}
+	cmd.AddCommand(
+		newCreateCommand(g),
+		newGetCommand(g),
+		newListCommand(g),
+		newUpdateCommand(g),
+		newDeleteCommand(g),
+		newWatchCommand(g),
+		newImportCommand(g),
+		newExportCommand(g),
+	)
+	return cmd
+}
+
+// run connects to the server selected for cmd and calls fn.
+func (g *globals) run(cmd *cobra.Command, fn func(ctx context.Context, b backend) error) error {
+	p, err := g.settings(cmd)
+	if err != nil {
+		return err
+	}
+	b, err := connect(p)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+	return fn(cmd.Context(), b)
+}
+
+// completeIDs completes Foo IDs, described by their title.
+func (g *globals) completeIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var ids []string
+	_ = g.run(cmd, func(ctx context.Context, b backend) error {
+		return b.List(ctx, maxCompletedIDs, func(foo *v1.Foo) error {
+			if len(ids) == maxCompletedIDs {
+				return io.EOF
+			}
+			ids = append(ids, fmt.Sprintf("%d\t%s", foo.Id, foo.Title))
+			return nil
+		})
+	})
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
+
+func parseIDs(args []string) ([]int64, error) {
+	ids := make([]int64, len(args))
+	for i, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid ID '%s'", arg)
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// fooInput holds the flags setting the fields of a created or updated Foo.
+type fooInput struct {
+	title, desc string
+	file        string
+	format      string
+}
+
+func (in *fooInput) addFlags(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	fs.StringVar(&in.title, "title", "", "Title of the Foo")
+	fs.StringVar(&in.desc, "desc", "", "Description of the Foo")
+	fs.StringVarP(&in.file, "file", "f", "", `JSON or YAML file holding the Foo, "-" for stdin`)
+	fs.StringVar(&in.format, "input-format", "", "Format of --file: json or yaml, detected by default")
+	_ = cmd.RegisterFlagCompletionFunc("input-format", fixedCompletion(formatJSON, formatYAML))
+}
+
+// apply sets the fields of foo given by --file, then those given by --title and --desc.
+func (in *fooInput) apply(cmd *cobra.Command, foo *v1.Foo) error {
+	if in.file != "" {
+		r, format, err := openInput(cmd, in.file, in.format)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		foos, err := decodeFoos(r, format)
+		if err != nil {
+			return err
+		}
+		if len(foos) != 1 {
+			return fmt.Errorf("%s holds %d Foos, want 1; use \"fooctl foo import\" for many", in.file, len(foos))
+		}
+		foo.Title, foo.Desc = foos[0].Title, foos[0].Desc
+	}
+	if cmd.Flags().Changed("title") {
+		foo.Title = in.title
+	}
+	if cmd.Flags().Changed("desc") {
+		foo.Desc = in.desc
+	}
+	return nil
+}
+
+// print writes foos to stdout in the --output format.
+func (g *globals) print(cmd *cobra.Command, foos ...*v1.Foo) error {
+	e, err := newEncoder(cmd.OutOrStdout(), g.output, len(foos) == 1)
+	if err != nil {
+		return err
+	}
+	for _, foo := range foos {
+		if err := e.Encode(foo); err != nil {
+			return err
+		}
+	}
+	return e.Close()
+}
+
+func newCreateCommand(g *globals) *cobra.Command {
+	in := &fooInput{}
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a Foo and print it",
+		Example: `  fooctl foo create --title "Title" --desc "Description"
+  echo '{"title": "Title"}' | fooctl foo create -f -`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			foo := &v1.Foo{}
+			if err := in.apply(cmd, foo); err != nil {
+				return err
+			}
+			return g.run(cmd, func(ctx context.Context, b backend) error {
+				id, err := b.Create(ctx, foo)
+				if err != nil {
+					return err
+				}
+				created, err := b.Get(ctx, id)
+				if err != nil {
+					return fmt.Errorf("created Foo %d, but failed to read it back: %v", id, err)
+				}
+				return g.print(cmd, created)
+			})
+		},
+	}
+	in.addFlags(cmd)
+	return cmd
+}
+
+func newGetCommand(g *globals) *cobra.Command {
+	return &cobra.Command{
+		Use:               "get ID...",
+		Short:             "Print Foos by ID",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: g.completeIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseIDs(args)
+			if err != nil {
+				return err
+			}
+			return g.run(cmd, func(ctx context.Context, b backend) error {
+				foos := make([]*v1.Foo, len(ids))
+				for i, id := range ids {
+					if foos[i], err = b.Get(ctx, id); err != nil {
+						return err
+					}
+				}
+				return g.print(cmd, foos...)
+			})
+		},
+	}
+}
+
+// list writes every Foo to w in format, fetching pageSize per call.
+func list(ctx context.Context, b backend, w io.Writer, format string, pageSize int32) error {
+	e, err := newEncoder(w, format, false)
+	if err != nil {
+		return err
+	}
+	if err := b.List(ctx, pageSize, e.Encode); err != nil {
+		return err
+	}
+	return e.Close()
+}
+
+func newListCommand(g *globals) *cobra.Command {
+	var pageSize int32
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Print every Foo",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return g.run(cmd, func(ctx context.Context, b backend) error {
+				return list(ctx, b, cmd.OutOrStdout(), g.output, pageSize)
+			})
+		},
+	}
+	cmd.Flags().Int32Var(&pageSize, "page-size", defaultListPageSize, "Number of Foos fetched per call, at most 1000")
+	return cmd
+}
+
+func newUpdateCommand(g *globals) *cobra.Command {
+	in := &fooInput{}
+	cmd := &cobra.Command{
+		Use:   "update ID",
+		Short: "Update the title or description of a Foo and print it",
+		Example: `  fooctl foo update 7 --desc "New description"
+  fooctl foo get 7 -o yaml > foo.yaml && vi foo.yaml && fooctl foo update 7 -f foo.yaml`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: g.completeIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseIDs(args)
+			if err != nil {
+				return err
+			}
+			return g.run(cmd, func(ctx context.Context, b backend) error {
+				// Fields not given keep their current value.
+				foo, err := b.Get(ctx, ids[0])
+				if err != nil {
+					return err
+				}
+				if err := in.apply(cmd, foo); err != nil {
+					return err
+				}
+				if err := b.Update(ctx, &v1.Foo{Id: foo.Id, Title: foo.Title, Desc: foo.Desc}); err != nil {
+					return err
+				}
+				if foo, err = b.Get(ctx, foo.Id); err != nil {
+					return err
+				}
+				return g.print(cmd, foo)
+			})
+		},
+	}
+	in.addFlags(cmd)
+	return cmd
+}
+
+func newDeleteCommand(g *globals) *cobra.Command {
+	return &cobra.Command{
+		Use:               "delete ID...",
+		Short:             "Delete Foos by ID",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: g.completeIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseIDs(args)
+			if err != nil {
+				return err
+			}
+			return g.run(cmd, func(ctx context.Context, b backend) error {
+				for _, id := range ids {
+					if err := b.Delete(ctx, id); err != nil {
+						return err
+					}
+					fmt.Fprintf(cmd.OutOrStdout(), "Deleted Foo %d\n", id)
+				}
+				return nil
+			})
+		},
+	}
+}
+
+// Events printed by watch.
+const (
+	eventAdded    = "ADDED"
+	eventModified = "MODIFIED"
+	eventDeleted  = "DELETED"
+)
+
+// watcher compares consecutive listings of every Foo.
+type watcher struct {
+	seen map[int64]*v1.Foo
+}
+
+// diff calls emit for every Foo added, modified or deleted since the previous listing.
+func (w *watcher) diff(foos []*v1.Foo, emit func(event string, foo *v1.Foo) error) error {
+	next := make(map[int64]*v1.Foo, len(foos))
+	for _, foo := range foos {
+		next[foo.Id] = foo
+		prev, ok := w.seen[foo.Id]
+		var err error
+		switch {
+		case !ok:
+			err = emit(eventAdded, foo)
+		case !proto.Equal(prev, foo):
+			err = emit(eventModified, foo)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for id, foo := range w.seen {
+		if _, ok := next[id]; !ok {
+			if err := emit(eventDeleted, foo); err != nil {
+				return err
+			}
+		}
+	}
+	w.seen = next
+	return nil
+}
+
+func newWatchCommand(g *globals) *cobra.Command {
+	var (
+		interval time.Duration
+		pageSize int32
+	)
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Print every Foo, then the Foos added, modified or deleted until interrupted",
+		Long: `Print every Foo, then the Foos added, modified or deleted until interrupted.
+
+The server has no change feed, so watch lists every Foo each --interval and prints
+the differences. Changes undone within an interval are missed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interval <= 0 {
+				return fmt.Errorf("invalid interval %v, want a positive duration", interval)
+			}
+			e, err := newEncoder(cmd.OutOrStdout(), g.output, false)
+			if err != nil {
+				return err
+			}
+			emit := func(event string, foo *v1.Foo) error {
+				e.event = event
+				return e.Encode(foo)
+			}
+			return g.run(cmd, func(ctx context.Context, b backend) error {
+				w := &watcher{}
+				t := time.NewTicker(interval)
+				defer t.Stop()
+				for {
+					var foos []*v1.Foo
+					err := b.List(ctx, pageSize, func(foo *v1.Foo) error {
+						foos = append(foos, foo)
+						return nil
+					})
+					if ctx.Err() != nil {
+						return nil
+					}
+					if err != nil {
+						return err
+					}
+					if err := w.diff(foos, emit); err != nil {
+						return err
+					}
+					if err := e.Flush(); err != nil {
+						return err
+					}
+					select {
+					case <-ctx.Done():
+						return nil
+					case <-t.C:
+					}
+				}
+			})
+		},
+	}
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "Time between listings")
+	cmd.Flags().Int32Var(&pageSize, "page-size", defaultListPageSize, "Number of Foos fetched per call, at most 1000")
+	return cmd
+}
+
+func newImportCommand(g *globals) *cobra.Command {
+	var (
+		file, format    string
+		continueOnError bool
+	)
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Create the Foos read from a JSON, YAML or CSV file",
+		Long: `Create the Foos read from a JSON, YAML or CSV file, such as one written by export.
+
+IDs and system fields in the file are ignored: every Foo is created anew.`,
+		Example: `  fooctl foo import -f foos.yaml
+  fooctl --profile staging foo export -o csv | fooctl --profile local foo import --input-format csv`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, format, err := openInput(cmd, file, format)
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+			foos, err := decodeFoos(r, format)
+			if err != nil {
+				return err
+			}
+			return g.run(cmd, func(ctx context.Context, b backend) error {
+				var failed int
+				for i, foo := range foos {
+					id, err := b.Create(ctx, &v1.Foo{Title: foo.Title, Desc: foo.Desc})
+					if err != nil {
+						if !continueOnError {
+							return fmt.Errorf("imported %d of %d Foos, then #%d failed: %v", i-failed, len(foos), i+1, err)
+						}
+						failed++
+						fmt.Fprintf(cmd.ErrOrStderr(), "Foo #%d failed: %v\n", i+1, err)
+						continue
+					}
+					fmt.Fprintf(cmd.OutOrStdout(), "Created Foo %d\n", id)
+				}
+				fmt.Fprintf(cmd.ErrOrStderr(), "Imported %d of %d Foos\n", len(foos)-failed, len(foos))
+				if failed > 0 {
+					return fmt.Errorf("%d Foos failed to import", failed)
+				}
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "-", `File to import, "-" for stdin`)
+	cmd.Flags().StringVar(&format, "input-format", "", "Format of --file: json, yaml or csv; by default named by its extension, else JSON or YAML detected")
+	cmd.Flags().BoolVar(&continueOnError, "continue-on-error", false, "Import the remaining Foos when one fails")
+	_ = cmd.RegisterFlagCompletionFunc("input-format", fixedCompletion(formatJSON, formatYAML, formatCSV))
+	return cmd
+}
+
+func newExportCommand(g *globals) *cobra.Command {
+	var (
+		file     string
+		pageSize int32
+	)
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write every Foo as JSON (the default), YAML or CSV, for import",
+		Example: `  fooctl foo export -f foos.json
+  fooctl foo export -o csv > foos.csv`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format := g.output
+			if !cmd.Flags().Changed("output") {
+				format = formatJSON
+			}
+			if format == formatTable {
+				return fmt.Errorf("cannot export as %s, choose %s, %s or %s", formatTable, formatJSON, formatYAML, formatCSV)
+			}
+			return g.run(cmd, func(ctx context.Context, b backend) error {
+				if file == "-" {
+					return list(ctx, b, cmd.OutOrStdout(), format, pageSize)
+				}
+				f, err := os.Create(file)
+				if err != nil {
+					return err
+				}
+				if err := list(ctx, b, f, format, pageSize); err != nil {
+					f.Close()
+					return err
+				}
+				return f.Close()
+			})
+		},
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "-", `File to write, "-" for stdout`)
+	cmd.Flags().Int32Var(&pageSize, "page-size", 1000, "Number of Foos fetched per call, at most 1000")
+	return cmd
+}
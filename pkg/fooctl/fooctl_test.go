@@ -0,0 +1,202 @@
+package fooctl
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/apierrors"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/rest"
+)
+
+// memoryServer is an in-memory Foo service; it returns copies, as responses are marshaled unlocked.
+type memoryServer struct {
+	v1.UnimplementedFooServiceServer
+	mu   sync.Mutex
+	foos map[int64]*v1.Foo
+	last int64
+}
+
+func (s *memoryServer) Create(ctx context.Context, req *v1.CreateRequest) (*v1.CreateResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last++
+	s.foos[s.last] = &v1.Foo{Id: s.last, Title: req.Foo.Title, Desc: req.Foo.Desc, SysFields: &v1.SystemFields{CreatedAt: timestamppb.Now()}}
+	return &v1.CreateResponse{ApiVersion: "v1", Id: s.last}, nil
+}
+
+func (s *memoryServer) Read(ctx context.Context, req *v1.ReadRequest) (*v1.ReadResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	foo, ok := s.foos[req.Id]
+	if !ok {
+		return nil, apierrors.NotFound("Foo", strconv.FormatInt(req.Id, 10))
+	}
+	return &v1.ReadResponse{ApiVersion: "v1", Foo: proto.Clone(foo).(*v1.Foo)}, nil
+}
+
+func (s *memoryServer) ReadAll(ctx context.Context, req *v1.ReadAllRequest) (*v1.ReadAllResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	after, _ := strconv.ParseInt(req.PageToken, 10, 64)
+	resp := &v1.ReadAllResponse{ApiVersion: "v1"}
+	for id := after + 1; id <= s.last && (req.PageSize == 0 || int32(len(resp.Foos)) < req.PageSize); id++ {
+		if foo, ok := s.foos[id]; ok {
+			resp.Foos = append(resp.Foos, proto.Clone(foo).(*v1.Foo))
+		}
+	}
+	if req.PageSize > 0 && int32(len(resp.Foos)) == req.PageSize {
+		resp.NextPageToken = strconv.FormatInt(resp.Foos[len(resp.Foos)-1].Id, 10)
+	}
+	return resp, nil
+}
+
+func (s *memoryServer) Update(ctx context.Context, req *v1.UpdateRequest) (*v1.UpdateResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	foo, ok := s.foos[req.Foo.Id]
+	if !ok {
+		return nil, apierrors.NotFound("Foo", strconv.FormatInt(req.Foo.Id, 10))
+	}
+	foo.Title, foo.Desc = req.Foo.Title, req.Foo.Desc
+	return &v1.UpdateResponse{ApiVersion: "v1", Count: 1}, nil
+}
+
+func (s *memoryServer) Delete(ctx context.Context, req *v1.DeleteRequest) (*v1.DeleteResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.foos[req.Id]; !ok {
+		return nil, apierrors.NotFound("Foo", strconv.FormatInt(req.Id, 10))
+	}
+	delete(s.foos, req.Id)
+	return &v1.DeleteResponse{ApiVersion: "v1", Count: 1}, nil
+}
+
+// startServers serves a memoryServer over gRPC and through the REST gateway.
+func startServers(t *testing.T) (grpcAddr, restURL string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	v1.RegisterFooServiceServer(s, &memoryServer{foos: map[int64]*v1.Foo{}})
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	ctx := context.Background()
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	h, err := rest.NewHandler(ctx, conn, func(context.Context) error { return nil }, rest.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return lis.Addr().String(), srv.URL
+}
+
+func run(t *testing.T, config, stdin string, args ...string) (string, error) {
+	cmd := NewCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetIn(strings.NewReader(stdin))
+	cmd.SetArgs(append([]string{"--config", config}, args...))
+	err := cmd.ExecuteContext(context.Background())
+	return out.String(), err
+}
+
+func TestCommands(t *testing.T) {
+	grpcAddr, restURL := startServers(t)
+	config := filepath.Join(t.TempDir(), "config.yaml")
+
+	if _, err := run(t, config, "", "profile", "set", "grpc", "--server", grpcAddr, "--insecure"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run(t, config, "", "profile", "set", "rest", "--server", restURL, "--transport", "rest"); err != nil {
+		t.Fatal(err)
+	}
+	if out, _ := run(t, config, "", "profile", "list"); !strings.HasPrefix(out, "* grpc\t") {
+		t.Fatalf("profile list = %q, want grpc current", out)
+	}
+
+	tests := []struct {
+		name    string
+		stdin   string
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		{name: "01 - gRPC create", args: []string{"foo", "create", "--title", "one", "--desc", "first"}, want: []string{"ID", "1", "one", "first"}},
+		{name: "02 - REST create from stdin", stdin: "title: two\n", args: []string{"-p", "rest", "foo", "create", "-f", "-", "-o", "json"}, want: []string{`"id": "2"`, `"title": "two"`}},
+		{name: "03 - REST import", stdin: `[{"title": "three"}, {"title": "four"}]`, args: []string{"-p", "rest", "foo", "import"}, want: []string{"Created Foo 3", "Created Foo 4"}},
+		{name: "04 - gRPC list pages", args: []string{"foo", "list", "--page-size", "3", "-o", "csv"}, want: []string{"id,title,desc", "1,one,first", "4,four,"}},
+		{name: "05 - REST update", args: []string{"-p", "rest", "foo", "update", "2", "--desc", "second", "-o", "yaml"}, want: []string{"title: two", "desc: second"}},
+		{name: "06 - gRPC get several", args: []string{"foo", "get", "1", "2", "-o", "yaml"}, want: []string{"- id: \"1\"", "- id: \"2\""}},
+		{name: "07 - REST delete", args: []string{"-p", "rest", "foo", "delete", "3"}, want: []string{"Deleted Foo 3"}},
+		{name: "08 - gRPC not found", args: []string{"foo", "get", "3"}, wantErr: "NotFound (NOT_FOUND): Foo '3' not found"},
+		{name: "09 - REST not found", args: []string{"-p", "rest", "foo", "delete", "3"}, wantErr: "NotFound (NOT_FOUND): Foo '3' not found"},
+		{name: "10 - REST export", args: []string{"-p", "rest", "foo", "export"}, want: []string{"[\n  {\n    \"id\": \"1\"", `"title": "four"`}},
+		{name: "11 - Unknown profile", args: []string{"-p", "nope", "foo", "list"}, wantErr: "unknown profile 'nope'"},
+		{name: "12 - REST loadtest", args: []string{"-p", "rest", "loadtest", "--warmup", "0s", "--duration", "100ms", "--seed", "2", "-o", "csv", "--report", filepath.Join(t.TempDir(), "runs.json")}, want: []string{"start,transport,server,mix", ",rest,http://", ",total,"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := run(t, config, tt.stdin, tt.args...)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output %q does not contain %q", out, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWatcher_diff(t *testing.T) {
+	foo := func(id int64, title string) *v1.Foo { return &v1.Foo{Id: id, Title: title} }
+	w := &watcher{}
+	tests := []struct {
+		name string
+		foos []*v1.Foo
+		want []string
+	}{
+		{name: "01 - Initial listing", foos: []*v1.Foo{foo(1, "a"), foo(2, "b")}, want: []string{"ADDED 1", "ADDED 2"}},
+		{name: "02 - Unchanged", foos: []*v1.Foo{foo(1, "a"), foo(2, "b")}},
+		{name: "03 - Changes", foos: []*v1.Foo{foo(2, "c"), foo(3, "d")}, want: []string{"MODIFIED 2", "ADDED 3", "DELETED 1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			_ = w.diff(tt.foos, func(event string, foo *v1.Foo) error {
+				got = append(got, event+" "+strconv.FormatInt(foo.Id, 10))
+				return nil
+			})
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("events = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
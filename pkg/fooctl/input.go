@@ -0,0 +1,203 @@
+package fooctl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"gopkg.in/yaml.v2"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+)
+
+var inputUnmarshaler = protojson.UnmarshalOptions{}
+
+// openInput opens file, "-" meaning stdin, and returns its format: the given one, else the
+// one named by its extension, else "" to detect it from the content.
+func openInput(cmd interface{ InOrStdin() io.Reader }, file, format string) (io.ReadCloser, string, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".json", ".ndjson":
+			format = formatJSON
+		case ".yaml", ".yml":
+			format = formatYAML
+		case ".csv":
+			format = formatCSV
+		}
+	}
+	if file == "-" {
+		return ioutil.NopCloser(cmd.InOrStdin()), format, nil
+	}
+	f, err := os.Open(file)
+	return f, format, err
+}
+
+// decodeFoos reads Foos as JSON (an object, an array or a stream of objects), YAML (a
+// mapping, a sequence or a stream of documents) or CSV with a header row as written by
+// --output=csv. An empty format detects JSON and YAML; CSV must be named.
+func decodeFoos(r io.Reader, format string) ([]*v1.Foo, error) {
+	br := bufio.NewReader(r)
+	if format == "" {
+		format = formatYAML
+		if c, err := firstByte(br); err == nil && (c == '{' || c == '[') {
+			format = formatJSON
+		}
+	}
+	switch format {
+	case formatJSON:
+		return decodeJSON(br)
+	case formatYAML:
+		return decodeYAML(br)
+	case formatCSV:
+		return decodeCSV(br)
+	}
+	return nil, fmt.Errorf("invalid input format '%s', want %s, %s or %s", format, formatJSON, formatYAML, formatCSV)
+}
+
+// firstByte peeks at the first non-space byte.
+func firstByte(br *bufio.Reader) (byte, error) {
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			return c, br.UnreadByte()
+		}
+	}
+}
+
+func unmarshalFoo(b []byte) (*v1.Foo, error) {
+	foo := &v1.Foo{}
+	if err := inputUnmarshaler.Unmarshal(b, foo); err != nil {
+		return nil, err
+	}
+	return foo, nil
+}
+
+func decodeJSON(r io.Reader) ([]*v1.Foo, error) {
+	var foos []*v1.Foo
+	dec := json.NewDecoder(r)
+	for {
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if err == io.EOF {
+			return foos, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON: %v", err)
+		}
+		items := []json.RawMessage{raw}
+		if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+			if err := json.Unmarshal(raw, &items); err != nil {
+				return nil, fmt.Errorf("invalid JSON: %v", err)
+			}
+		}
+		for _, item := range items {
+			foo, err := unmarshalFoo(item)
+			if err != nil {
+				return nil, fmt.Errorf("invalid Foo #%d: %v", len(foos)+1, err)
+			}
+			foos = append(foos, foo)
+		}
+	}
+}
+
+func decodeYAML(r io.Reader) ([]*v1.Foo, error) {
+	var foos []*v1.Foo
+	dec := yaml.NewDecoder(r)
+	for {
+		var doc interface{}
+		err := dec.Decode(&doc)
+		if err == io.EOF {
+			return foos, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid YAML: %v", err)
+		}
+		items, ok := doc.([]interface{})
+		if !ok {
+			items = []interface{}{doc}
+		}
+		for _, item := range items {
+			b, err := json.Marshal(jsonValue(item))
+			if err == nil {
+				var foo *v1.Foo
+				if foo, err = unmarshalFoo(b); err == nil {
+					foos = append(foos, foo)
+					continue
+				}
+			}
+			return nil, fmt.Errorf("invalid Foo #%d: %v", len(foos)+1, err)
+		}
+	}
+}
+
+// jsonValue converts the maps decoded by yaml.v2, keyed by interface{}, into JSON objects.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = jsonValue(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range v {
+			v[i] = jsonValue(e)
+		}
+	}
+	return v
+}
+
+func decodeCSV(r io.Reader) ([]*v1.Foo, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+	cols := map[string]int{}
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := cols["title"]; !ok {
+		return nil, errors.New("invalid CSV: the header has no title column")
+	}
+
+	var foos []*v1.Foo
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return foos, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %v", err)
+		}
+		get := func(name string) string {
+			if i, ok := cols[name]; ok {
+				return rec[i]
+			}
+			return ""
+		}
+		foo := &v1.Foo{Title: get("title"), Desc: get("desc")}
+		if id := get("id"); id != "" {
+			if foo.Id, err = strconv.ParseInt(id, 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid Foo #%d: invalid id '%s'", len(foos)+1, id)
+			}
+		}
+		foos = append(foos, foo)
+	}
+}
@@ -0,0 +1,41 @@
+package fooctl
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_decodeFoos(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		format  string
+		want    []string
+		wantErr bool
+	}{
+		{name: "01 - JSON object", input: `{"title": "a", "desc": "b"}`, want: []string{"a/b"}},
+		{name: "02 - JSON array", input: ` [{"title": "a"}, {"id": "7", "title": "b"}]`, want: []string{"a/", "b/"}},
+		{name: "03 - JSON stream", input: "{\"title\": \"a\"}\n{\"title\": \"b\"}\n", want: []string{"a/", "b/"}},
+		{name: "04 - YAML documents", input: "title: a\ndesc: b\n---\ntitle: c\n", want: []string{"a/b", "c/"}},
+		{name: "05 - YAML sequence", input: "- id: 1\n  title: a\n  sys_fields:\n    created_at: 2021-11-01T00:00:00Z\n- title: b\n", want: []string{"a/", "b/"}},
+		{name: "06 - CSV", input: "id,title,desc\n1,a,\"b, c\"\n,d,\n", format: formatCSV, want: []string{"a/b, c", "d/"}},
+		{name: "07 - Unknown field", input: `{"name": "a"}`, wantErr: true},
+		{name: "08 - CSV without title", input: "id,desc\n1,b\n", format: formatCSV, wantErr: true},
+		{name: "09 - Empty", input: "", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			foos, err := decodeFoos(strings.NewReader(tt.input), tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeFoos() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, foo := range foos {
+				got = append(got, foo.Title+"/"+foo.Desc)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("decodeFoos() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package fooctl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/client"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/loadtest"
+)
+
+// writeReport writes r to file as JSON or CSV, by its extension. CSV reports are appended
+// to an existing file, to compare runs.
+func writeReport(r *loadtest.Report, file string) error {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		f, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		if err := r.WriteJSON(f); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	case ".csv":
+		f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+		fi, err := f.Stat()
+		if err == nil {
+			err = r.WriteCSV(f, fi.Size() == 0)
+		}
+		if err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+	return fmt.Errorf("invalid report file '%s', want a .json or .csv extension", file)
+}
+
+func newLoadtestCommand(g *globals) *cobra.Command {
+	var (
+		opts   loadtest.Options
+		mix    string
+		report string
+	)
+	cmd := &cobra.Command{
+		Use:   "loadtest",
+		Short: "Drive a mix of calls against the server and report latencies and errors",
+		Long: `Drive a mix of calls against the server and report latencies and errors.
+
+Without --rps, --concurrency workers make calls back to back. With --rps, calls are
+made at that rate by up to --concurrency workers, and latencies count from when each
+call was due, so they include the time spent waiting for a busy server.
+
+Before the run, --seed Foos are created for Read, Update and Delete to target; the
+Foos created by the run are deleted at the end unless --cleanup=false. Calls are
+not retried.`,
+		Example: `  fooctl loadtest --concurrency 32 --duration 5m
+  fooctl loadtest --rps 500 --mix create=1,read=8,readall=1 --report runs.csv
+  fooctl loadtest --transport rest --rps 200 -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			if opts.Mix, err = loadtest.ParseMix(mix); err != nil {
+				return err
+			}
+			if g.output != formatTable && g.output != formatJSON && g.output != formatCSV {
+				return fmt.Errorf("invalid output format '%s' for loadtest, want %s, %s or %s", g.output, formatTable, formatJSON, formatCSV)
+			}
+			p, err := g.settings(cmd)
+			if err != nil {
+				return err
+			}
+			b, err := connect(p, client.WithRetry(client.RetryPolicy{}))
+			if err != nil {
+				return err
+			}
+			defer b.Close()
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "Running %s against %s: %v warm-up, then %v\n", opts.Mix, p.Server, opts.Warmup, opts.Duration)
+			r, err := loadtest.Run(cmd.Context(), b, opts)
+			if err != nil {
+				return err
+			}
+			r.Transport, r.Server = p.Transport, p.Server
+
+			if report != "" {
+				if err := writeReport(r, report); err != nil {
+					return err
+				}
+			}
+			switch g.output {
+			case formatJSON:
+				return r.WriteJSON(cmd.OutOrStdout())
+			case formatCSV:
+				return r.WriteCSV(cmd.OutOrStdout(), true)
+			}
+			return r.WriteText(cmd.OutOrStdout())
+		},
+	}
+	fs := cmd.Flags()
+	fs.StringVar(&mix, "mix", loadtest.DefaultMix.String(), "Weights of the calls made")
+	fs.IntVarP(&opts.Concurrency, "concurrency", "c", 8, "Number of concurrent workers")
+	fs.Float64Var(&opts.RPS, "rps", 0, "Target calls per second; 0 runs as fast as --concurrency allows")
+	fs.DurationVar(&opts.Warmup, "warmup", 10*time.Second, "Load run before recording")
+	fs.DurationVar(&opts.Duration, "duration", time.Minute, "Recorded load")
+	fs.IntVar(&opts.Seed, "seed", 100, "Foos created before the run")
+	fs.Int32Var(&opts.PageSize, "page-size", defaultListPageSize, "Foos read by each ReadAll")
+	fs.BoolVar(&opts.Cleanup, "cleanup", true, "Delete the Foos created by the run")
+	fs.StringVar(&report, "report", "", "Also write the report to this .json file, or append it to this .csv file")
+	return cmd
+}
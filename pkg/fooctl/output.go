@@ -0,0 +1,204 @@
+package fooctl
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gopkg.in/yaml.v2"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+)
+
+// Output formats selectable with --output.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+	formatCSV   = "csv"
+)
+
+var formats = []string{formatTable, formatJSON, formatYAML, formatCSV}
+
+// csvHeader names the columns of CSV output, which import reads back.
+var csvHeader = []string{"id", "title", "desc", "created_by", "updated_by", "created_at", "updated_at"}
+
+// tableEscaper keeps every row of a table on one line.
+var tableEscaper = strings.NewReplacer("\n", `\n`, "\r", `\r`, "\t", " ")
+
+var outputMarshaler = protojson.MarshalOptions{UseProtoNames: true}
+
+// encoder writes Foos in one of the output formats. Lists are written as a JSON array, a
+// YAML sequence or one row per Foo; a single Foo is written as a JSON or YAML object.
+type encoder struct {
+	w      io.Writer
+	format string
+	single bool
+	// event is the column prepended to every row by watch, empty otherwise.
+	event string
+	n     int
+	table *tabwriter.Writer
+	csv   *csv.Writer
+}
+
+func newEncoder(w io.Writer, format string, single bool) (*encoder, error) {
+	e := &encoder{w: w, format: format, single: single}
+	switch format {
+	case formatTable:
+		e.table = tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	case formatCSV:
+		e.csv = csv.NewWriter(w)
+	case formatJSON, formatYAML:
+	default:
+		return nil, fmt.Errorf("invalid output format '%s', want one of %s", format, strings.Join(formats, ", "))
+	}
+	return e, nil
+}
+
+func formatTime(ts *timestamppb.Timestamp) string {
+	if ts == nil {
+		return ""
+	}
+	return ts.AsTime().Format(time.RFC3339)
+}
+
+func row(foo *v1.Foo) []string {
+	sys := foo.GetSysFields()
+	return []string{
+		strconv.FormatInt(foo.GetId(), 10), foo.GetTitle(), foo.GetDesc(),
+		sys.GetCreatedBy(), sys.GetUpdatedBy(), formatTime(sys.GetCreatedAt()), formatTime(sys.GetUpdatedAt()),
+	}
+}
+
+// yamlValue converts foo into a YAML mapping keeping the proto field order.
+func yamlValue(foo *v1.Foo) (yaml.MapSlice, error) {
+	b, err := outputMarshaler.Marshal(foo)
+	if err != nil {
+		return nil, err
+	}
+	var m yaml.MapSlice
+	// JSON is valid YAML.
+	err = yaml.Unmarshal(b, &m)
+	return m, err
+}
+
+func (e *encoder) header() []string {
+	if e.event == "" {
+		return csvHeader
+	}
+	return append([]string{"event"}, csvHeader...)
+}
+
+// Encode writes foo.
+func (e *encoder) Encode(foo *v1.Foo) error {
+	first := e.n == 0
+	e.n++
+	switch e.format {
+	case formatTable:
+		cols := row(foo)
+		for i, c := range cols {
+			cols[i] = tableEscaper.Replace(c)
+		}
+		if first {
+			fmt.Fprintln(e.table, strings.ToUpper(strings.Join(e.header(), "\t")))
+		}
+		if e.event != "" {
+			cols = append([]string{e.event}, cols...)
+		}
+		_, err := fmt.Fprintln(e.table, strings.Join(cols, "\t"))
+		return err
+	case formatCSV:
+		if first {
+			if err := e.csv.Write(e.header()); err != nil {
+				return err
+			}
+		}
+		cols := row(foo)
+		if e.event != "" {
+			cols = append([]string{e.event}, cols...)
+		}
+		return e.csv.Write(cols)
+	case formatJSON:
+		b, err := outputMarshaler.Marshal(foo)
+		if err != nil {
+			return err
+		}
+		// protojson varies its whitespace between runs; normalize it.
+		var out bytes.Buffer
+		switch {
+		case e.event != "":
+			err = json.Compact(&out, []byte(fmt.Sprintf(`{"event":%q,"foo":%s}`, e.event, b)))
+			out.WriteByte('\n')
+		case e.single:
+			err = json.Indent(&out, b, "", "  ")
+			out.WriteByte('\n')
+		default:
+			out.WriteString(",\n  ")
+			if first {
+				out.Reset()
+				out.WriteString("[\n  ")
+			}
+			err = json.Indent(&out, b, "  ", "  ")
+		}
+		if err != nil {
+			return err
+		}
+		_, err = e.w.Write(out.Bytes())
+		return err
+	case formatYAML:
+		m, err := yamlValue(foo)
+		if err != nil {
+			return err
+		}
+		var v interface{} = []yaml.MapSlice{m}
+		switch {
+		case e.event != "":
+			fmt.Fprintln(e.w, "---")
+			v = yaml.MapSlice{{Key: "event", Value: e.event}, {Key: "foo", Value: m}}
+		case e.single:
+			v = m
+		}
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = e.w.Write(b)
+		return err
+	}
+	return nil
+}
+
+// Flush writes buffered rows; watch calls it after every poll.
+func (e *encoder) Flush() error {
+	switch e.format {
+	case formatTable:
+		return e.table.Flush()
+	case formatCSV:
+		e.csv.Flush()
+		return e.csv.Error()
+	}
+	return nil
+}
+
+// Close ends the list and flushes the output.
+func (e *encoder) Close() error {
+	if !e.single && e.event == "" {
+		switch {
+		case e.format == formatJSON && e.n == 0:
+			fmt.Fprintln(e.w, "[]")
+		case e.format == formatJSON:
+			fmt.Fprintln(e.w, "\n]")
+		case e.format == formatYAML && e.n == 0:
+			fmt.Fprintln(e.w, "[]")
+		}
+	}
+	return e.Flush()
+}
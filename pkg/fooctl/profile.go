@@ -0,0 +1,197 @@
+package fooctl
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// Transports selectable with --transport.
+const (
+	TransportGRPC = "grpc"
+	TransportREST = "rest"
+)
+
+// Profile holds the settings for one server.
+type Profile struct {
+	Server    string        `yaml:"server,omitempty"`
+	Transport string        `yaml:"transport,omitempty"`
+	Token     string        `yaml:"token,omitempty"`
+	APIKey    string        `yaml:"api-key,omitempty"`
+	Tenant    string        `yaml:"tenant,omitempty"`
+	Insecure  bool          `yaml:"insecure,omitempty"`
+	CACert    string        `yaml:"ca-cert,omitempty"`
+	Timeout   time.Duration `yaml:"timeout,omitempty"`
+}
+
+// Config is the fooctl config file, by default $XDG_CONFIG_HOME/fooctl/config.yaml:
+//
+//	current-profile: prod
+//	profiles:
+//	  local:
+//	    server: localhost:9090
+//	    insecure: true
+//	  prod:
+//	    server: foo.example.com:443
+//	    token: s3cr3t
+type Config struct {
+	CurrentProfile string              `yaml:"current-profile,omitempty"`
+	Profiles       map[string]*Profile `yaml:"profiles,omitempty"`
+}
+
+// defaultConfigFile returns the config file used unless --config or FOOCTL_CONFIG is set.
+func defaultConfigFile() string {
+	if f := os.Getenv("FOOCTL_CONFIG"); f != "" {
+		return f
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "fooctl", "config.yaml")
+}
+
+// loadConfig reads the config file; a missing file is an empty config.
+func loadConfig(file string) (*Config, error) {
+	cfg := &Config{Profiles: map[string]*Profile{}}
+	if file == "" {
+		return cfg, nil
+	}
+	b, err := ioutil.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.UnmarshalStrict(b, cfg); err != nil {
+		return nil, fmt.Errorf("invalid config file '%s': %v", file, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]*Profile{}
+	}
+	return cfg, nil
+}
+
+// save writes the config file readable by its owner only, since profiles hold credentials.
+func (cfg *Config) save(file string) error {
+	if file == "" {
+		return errors.New("no config file, set --config or FOOCTL_CONFIG")
+	}
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, b, 0600)
+}
+
+func (cfg *Config) names() []string {
+	var names []string
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newProfileCommand(g *globals) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage named server profiles",
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "set NAME",
+			Short: "Create or update a profile from the --server, --transport and credential flags",
+			Example: `  fooctl profile set prod --server foo.example.com:443 --token "$TOKEN"
+  fooctl profile set local --server localhost:9090 --insecure`,
+			Args: cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				cfg, err := loadConfig(g.configFile)
+				if err != nil {
+					return err
+				}
+				p := cfg.Profiles[args[0]]
+				if p == nil {
+					p = &Profile{}
+					cfg.Profiles[args[0]] = p
+				}
+				if err := g.override(cmd, p); err != nil {
+					return err
+				}
+				if cfg.CurrentProfile == "" {
+					cfg.CurrentProfile = args[0]
+				}
+				return cfg.save(g.configFile)
+			},
+		},
+		&cobra.Command{
+			Use:               "use NAME",
+			Short:             "Select the profile used when --profile is not given",
+			Args:              cobra.ExactArgs(1),
+			ValidArgsFunction: g.completeProfiles,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				cfg, err := loadConfig(g.configFile)
+				if err != nil {
+					return err
+				}
+				if cfg.Profiles[args[0]] == nil {
+					return fmt.Errorf("unknown profile '%s'", args[0])
+				}
+				cfg.CurrentProfile = args[0]
+				return cfg.save(g.configFile)
+			},
+		},
+		&cobra.Command{
+			Use:               "delete NAME",
+			Short:             "Delete a profile",
+			Args:              cobra.ExactArgs(1),
+			ValidArgsFunction: g.completeProfiles,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				cfg, err := loadConfig(g.configFile)
+				if err != nil {
+					return err
+				}
+				if cfg.Profiles[args[0]] == nil {
+					return fmt.Errorf("unknown profile '%s'", args[0])
+				}
+				delete(cfg.Profiles, args[0])
+				if cfg.CurrentProfile == args[0] {
+					cfg.CurrentProfile = ""
+				}
+				return cfg.save(g.configFile)
+			},
+		},
+		&cobra.Command{
+			Use:   "list",
+			Short: "List profiles; the current one is marked with *",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				cfg, err := loadConfig(g.configFile)
+				if err != nil {
+					return err
+				}
+				for _, name := range cfg.names() {
+					mark := " "
+					if name == cfg.CurrentProfile {
+						mark = "*"
+					}
+					p := cfg.Profiles[name]
+					fmt.Fprintf(cmd.OutOrStdout(), "%s %s\t%s\t%s\n", mark, name, p.Server, p.Transport)
+				}
+				return nil
+			},
+		},
+	)
+	return cmd
+}
@@ -0,0 +1,225 @@
+package fooctl
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/code"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/client"
+)
+
+// maxErrorBody bounds how much of an error response is read.
+const maxErrorBody = 1 << 20
+
+// maxIdleConns keeps connections open for as many concurrent calls, e.g. by loadtest.
+const maxIdleConns = 256
+
+// restBackend calls the HTTP/REST gateway.
+type restBackend struct {
+	base    string
+	http    *http.Client
+	header  http.Header
+	timeout time.Duration
+}
+
+func newRESTBackend(p Profile, config *tls.Config) (*restBackend, error) {
+	base := p.Server
+	if !strings.Contains(base, "://") {
+		scheme := "https://"
+		if p.Insecure {
+			scheme = "http://"
+		}
+		base = scheme + base
+	}
+	u, err := url.Parse(base)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid REST server '%s', want host:port or an http(s):// URL", p.Server)
+	}
+
+	header := http.Header{}
+	header.Set("User-Agent", userAgent())
+	if p.Token != "" {
+		header.Set("Authorization", "Bearer "+p.Token)
+	}
+	if p.APIKey != "" {
+		header.Set("X-Api-Key", p.APIKey)
+	}
+	if p.Tenant != "" {
+		header.Set("X-Tenant-Id", p.Tenant)
+	}
+	return &restBackend{
+		base: strings.TrimSuffix(base, "/") + "/api/v1",
+		http: &http.Client{Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			TLSClientConfig:     config,
+			MaxIdleConns:        maxIdleConns,
+			MaxIdleConnsPerHost: maxIdleConns,
+			IdleConnTimeout:     90 * time.Second,
+		}},
+		header:  header,
+		timeout: p.Timeout,
+	}, nil
+}
+
+var (
+	restMarshaler   = protojson.MarshalOptions{UseProtoNames: true}
+	restUnmarshaler = protojson.UnmarshalOptions{DiscardUnknown: true}
+)
+
+// call sends req, if any, as the body of method path and decodes the response into resp.
+func (b *restBackend) call(ctx context.Context, method, path string, req, resp proto.Message) error {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+
+	var body io.Reader
+	if req != nil {
+		bs, err := restMarshaler.Marshal(req)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(bs)
+	}
+	r, err := http.NewRequestWithContext(ctx, method, b.base+path, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range b.header {
+		r.Header[k] = v
+	}
+	if req != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := b.http.Do(r)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return client.FromError(status.Error(codes.DeadlineExceeded, err.Error()))
+		}
+		return client.FromError(status.Error(codes.Unavailable, err.Error()))
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return restError(res)
+	}
+	bs, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	return restUnmarshaler.Unmarshal(bs, resp)
+}
+
+// restError decodes the gateway's error envelope into a *client.Error, like the gRPC status it carries.
+func restError(res *http.Response) error {
+	var envelope struct {
+		Error struct {
+			Status    string            `json:"status"`
+			Message   string            `json:"message"`
+			RequestID string            `json:"request_id"`
+			Details   []json.RawMessage `json:"details"`
+		} `json:"error"`
+	}
+	bs, _ := ioutil.ReadAll(io.LimitReader(res.Body, maxErrorBody))
+	if err := json.Unmarshal(bs, &envelope); err != nil || envelope.Error.Status == "" {
+		return &client.Error{Code: httpCode(res.StatusCode), Message: fmt.Sprintf("%s: %s", res.Status, bytes.TrimSpace(bs))}
+	}
+
+	st := &spb.Status{Code: code.Code_value[envelope.Error.Status], Message: envelope.Error.Message}
+	for _, d := range envelope.Error.Details {
+		a := &anypb.Any{}
+		// Details of unknown types are skipped, as the gRPC client would.
+		if restUnmarshaler.Unmarshal(d, a) == nil {
+			st.Details = append(st.Details, a)
+		}
+	}
+	err := client.FromError(status.ErrorProto(st))
+	if e, ok := err.(*client.Error); ok && e.RequestID == "" {
+		e.RequestID = envelope.Error.RequestID
+	}
+	return err
+}
+
+// httpCode maps the status of a response that is not an error envelope, e.g. from a proxy.
+func httpCode(status int) codes.Code {
+	switch status {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return codes.Unavailable
+	}
+	return codes.Unknown
+}
+
+func (b *restBackend) Create(ctx context.Context, foo *v1.Foo) (int64, error) {
+	resp := &v1.CreateResponse{}
+	err := b.call(ctx, http.MethodPost, "/foo", &v1.CreateRequest{ApiVersion: client.APIVersion, Foo: foo}, resp)
+	return resp.Id, err
+}
+
+func (b *restBackend) Get(ctx context.Context, id int64) (*v1.Foo, error) {
+	resp := &v1.ReadResponse{}
+	if err := b.call(ctx, http.MethodGet, "/foo/"+strconv.FormatInt(id, 10), nil, resp); err != nil {
+		return nil, err
+	}
+	return resp.Foo, nil
+}
+
+func (b *restBackend) Update(ctx context.Context, foo *v1.Foo) error {
+	return b.call(ctx, http.MethodPut, "/foo/"+strconv.FormatInt(foo.Id, 10), &v1.UpdateRequest{ApiVersion: client.APIVersion, Foo: foo}, &v1.UpdateResponse{})
+}
+
+func (b *restBackend) Delete(ctx context.Context, id int64) error {
+	return b.call(ctx, http.MethodDelete, "/foo/"+strconv.FormatInt(id, 10), nil, &v1.DeleteResponse{})
+}
+
+func (b *restBackend) List(ctx context.Context, pageSize int32, fn func(*v1.Foo) error) error {
+	token := ""
+	for {
+		q := url.Values{"page_size": {strconv.Itoa(int(pageSize))}}
+		if token != "" {
+			q.Set("page_token", token)
+		}
+		resp := &v1.ReadAllResponse{}
+		if err := b.call(ctx, http.MethodGet, "/foo/all?"+q.Encode(), nil, resp); err != nil {
+			return err
+		}
+		for _, foo := range resp.Foos {
+			if err := fn(foo); err != nil {
+				return err
+			}
+		}
+		if token = resp.NextPageToken; token == "" {
+			return nil
+		}
+	}
+}
+
+func (b *restBackend) Close() error {
+	b.http.CloseIdleConnections()
+	return nil
+}
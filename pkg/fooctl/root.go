@@ -0,0 +1,151 @@
+// Package fooctl implements the fooctl command-line tool for the Foo service.
+package fooctl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultGRPCServer = "localhost:9090"
+	defaultRESTServer = "localhost:8080"
+	defaultTimeout    = 10 * time.Second
+)
+
+// globals holds the persistent flags shared by every command.
+type globals struct {
+	configFile string
+	profile    string
+	flags      Profile
+	output     string
+}
+
+// NewCommand returns the fooctl root command.
+func NewCommand() *cobra.Command {
+	g := &globals{}
+	cmd := &cobra.Command{
+		Use:   "fooctl",
+		Short: "Manage Foos over gRPC or REST",
+		Long: `fooctl manages Foos over gRPC or REST.
+
+Connection settings come from, in order of precedence, the command-line flags, the
+FOOCTL_TOKEN and FOOCTL_API_KEY environment variables, and the selected profile of
+the config file (see "fooctl profile").`,
+		SilenceUsage: true,
+	}
+
+	fs := cmd.PersistentFlags()
+	fs.StringVar(&g.configFile, "config", defaultConfigFile(), "Config file holding the profiles, also set by FOOCTL_CONFIG")
+	fs.StringVarP(&g.profile, "profile", "p", "", "Profile to use instead of the current one")
+	fs.StringVarP(&g.flags.Server, "server", "s", "", fmt.Sprintf("Server address, %s for gRPC or %s for REST by default; REST also takes an http:// or https:// URL", defaultGRPCServer, defaultRESTServer))
+	fs.StringVar(&g.flags.Transport, "transport", "", "Transport to the server: grpc (default) or rest")
+	fs.StringVar(&g.flags.Token, "token", "", "Bearer token sent with every call, also set by FOOCTL_TOKEN")
+	fs.StringVar(&g.flags.APIKey, "api-key", "", "API key sent with every call, also set by FOOCTL_API_KEY")
+	fs.StringVar(&g.flags.Tenant, "tenant", "", "Tenant recorded in the audit trail")
+	fs.BoolVar(&g.flags.Insecure, "insecure", false, "Connect without TLS")
+	fs.StringVar(&g.flags.CACert, "ca-cert", "", "PEM file of the CA certificates verifying the server, instead of the system roots")
+	fs.DurationVar(&g.flags.Timeout, "timeout", 0, "Timeout of each call (default 10s)")
+	fs.StringVarP(&g.output, "output", "o", formatTable, "Output format: "+strings.Join(formats, ", "))
+
+	_ = cmd.RegisterFlagCompletionFunc("profile", g.completeProfiles)
+	_ = cmd.RegisterFlagCompletionFunc("transport", fixedCompletion(TransportGRPC, TransportREST))
+	_ = cmd.RegisterFlagCompletionFunc("output", fixedCompletion(formats...))
+
+	cmd.AddCommand(newFooCommand(g), newProfileCommand(g), newLoadtestCommand(g))
+	return cmd
+}
+
+func fixedCompletion(values ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func (g *globals) completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := loadConfig(g.configFile)
+	if err != nil || len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return cfg.names(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// override copies the connection flags given on the command line into p.
+func (g *globals) override(cmd *cobra.Command, p *Profile) error {
+	fs := cmd.Flags()
+	if fs.Changed("server") {
+		p.Server = g.flags.Server
+	}
+	if fs.Changed("transport") {
+		if g.flags.Transport != TransportGRPC && g.flags.Transport != TransportREST {
+			return fmt.Errorf("invalid transport '%s', want %s or %s", g.flags.Transport, TransportGRPC, TransportREST)
+		}
+		p.Transport = g.flags.Transport
+	}
+	if fs.Changed("token") {
+		p.Token = g.flags.Token
+	}
+	if fs.Changed("api-key") {
+		p.APIKey = g.flags.APIKey
+	}
+	if fs.Changed("tenant") {
+		p.Tenant = g.flags.Tenant
+	}
+	if fs.Changed("insecure") {
+		p.Insecure = g.flags.Insecure
+	}
+	if fs.Changed("ca-cert") {
+		p.CACert = g.flags.CACert
+	}
+	if fs.Changed("timeout") {
+		p.Timeout = g.flags.Timeout
+	}
+	return nil
+}
+
+// settings resolves the connection settings of cmd from its flags, the environment and the profile.
+func (g *globals) settings(cmd *cobra.Command) (Profile, error) {
+	cfg, err := loadConfig(g.configFile)
+	if err != nil {
+		return Profile{}, err
+	}
+	var p Profile
+	name := g.profile
+	if name == "" {
+		name = cfg.CurrentProfile
+	}
+	if name != "" {
+		profile := cfg.Profiles[name]
+		if profile == nil {
+			return Profile{}, fmt.Errorf("unknown profile '%s'", name)
+		}
+		p = *profile
+	}
+
+	if v, ok := os.LookupEnv("FOOCTL_TOKEN"); ok {
+		p.Token = v
+	}
+	if v, ok := os.LookupEnv("FOOCTL_API_KEY"); ok {
+		p.APIKey = v
+	}
+	if err := g.override(cmd, &p); err != nil {
+		return Profile{}, err
+	}
+
+	if p.Transport == "" {
+		p.Transport = TransportGRPC
+	}
+	if p.Server == "" {
+		p.Server = defaultGRPCServer
+		if p.Transport == TransportREST {
+			p.Server = defaultRESTServer
+		}
+	}
+	if p.Timeout == 0 {
+		p.Timeout = defaultTimeout
+	}
+	return p, nil
+}
@@ -0,0 +1,328 @@
+// Package loadtest drives a configurable mix of calls against the Foo service and reports
+// their throughput, latency percentiles and errors.
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+	"google.golang.org/grpc/codes"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/client"
+)
+
+// Client makes the calls; fooctl implements it over gRPC and REST. Errors should carry a
+// gRPC status code, as *client.Error does.
+type Client interface {
+	Create(ctx context.Context, foo *v1.Foo) (int64, error)
+	Get(ctx context.Context, id int64) (*v1.Foo, error)
+	Update(ctx context.Context, foo *v1.Foo) error
+	Delete(ctx context.Context, id int64) error
+	List(ctx context.Context, pageSize int32, fn func(*v1.Foo) error) error
+}
+
+// Options configures a run.
+type Options struct {
+	Mix Mix
+	// Concurrency is the number of workers. Without RPS each makes calls back to back;
+	// with RPS it bounds the calls in flight.
+	Concurrency int
+	// RPS is the target rate of calls per second, 0 to run as fast as Concurrency allows.
+	RPS float64
+	// Warmup runs the load without recording it, e.g. to fill connection pools and caches.
+	Warmup   time.Duration
+	Duration time.Duration
+	// Seed is the number of Foos created before the run for Read, Update and Delete to target.
+	Seed int
+	// PageSize is the number of Foos read by each ReadAll.
+	PageSize int32
+	// Cleanup deletes the Foos created by the run that are left at the end.
+	Cleanup bool
+}
+
+func (o Options) validate() error {
+	switch {
+	case o.Mix.total() == 0:
+		return errors.New("the mix has no positive weight")
+	case o.Concurrency < 1:
+		return fmt.Errorf("invalid concurrency %d, want at least 1", o.Concurrency)
+	case o.RPS < 0:
+		return fmt.Errorf("invalid RPS %v, want 0 or more", o.RPS)
+	case o.Warmup < 0 || o.Duration <= 0:
+		return fmt.Errorf("invalid warm-up %v or duration %v, want a positive duration", o.Warmup, o.Duration)
+	case o.Seed < 0:
+		return fmt.Errorf("invalid seed %d, want 0 or more", o.Seed)
+	case o.PageSize < 1 || o.PageSize > 1000:
+		return fmt.Errorf("invalid page size %d, want 1 to 1000", o.PageSize)
+	}
+	return nil
+}
+
+// Histograms record latencies from 1µs to 1 minute with 3 significant digits.
+const (
+	minLatency = int64(time.Microsecond)
+	maxLatency = int64(time.Minute)
+	sigFigs    = 3
+)
+
+// opStats accumulates the outcome of one Op.
+type opStats struct {
+	latency *hdrhistogram.Histogram
+	codes   map[string]int64
+}
+
+func newOpStats() *opStats {
+	return &opStats{latency: hdrhistogram.New(minLatency, maxLatency, sigFigs), codes: map[string]int64{}}
+}
+
+func (s *opStats) record(d time.Duration, err error) {
+	if int64(d) > maxLatency {
+		d = time.Duration(maxLatency)
+	}
+	_ = s.latency.RecordValue(int64(d))
+	s.codes[client.Code(err).String()]++
+}
+
+func (s *opStats) merge(o *opStats) {
+	s.latency.Merge(o.latency)
+	for code, n := range o.codes {
+		s.codes[code] += n
+	}
+}
+
+// stats is owned by one worker, so recording needs no locking.
+type stats map[Op]*opStats
+
+func (s stats) op(op Op) *opStats {
+	os, ok := s[op]
+	if !ok {
+		os = newOpStats()
+		s[op] = os
+	}
+	return os
+}
+
+// pool holds the IDs of the Foos created by the run.
+type pool struct {
+	mu  sync.Mutex
+	ids []int64
+}
+
+func (p *pool) add(id int64) {
+	p.mu.Lock()
+	p.ids = append(p.ids, id)
+	p.mu.Unlock()
+}
+
+// pick returns a random ID, removing it if take is set, or false if the pool is empty.
+func (p *pool) pick(r *rand.Rand, take bool) (int64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.ids) == 0 {
+		return 0, false
+	}
+	i := r.Intn(len(p.ids))
+	id := p.ids[i]
+	if take {
+		p.ids[i] = p.ids[len(p.ids)-1]
+		p.ids = p.ids[:len(p.ids)-1]
+	}
+	return id, true
+}
+
+func (p *pool) drain() []int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ids := p.ids
+	p.ids = nil
+	return ids
+}
+
+var errPageRead = errors.New("page read")
+
+// runner makes calls for one run.
+type runner struct {
+	c      Client
+	opts   Options
+	picker picker
+	ids    pool
+	seq    int64
+}
+
+// do makes one call of op, or of Create when there is no Foo left to target, and returns
+// the Op actually made.
+func (r *runner) do(ctx context.Context, rnd *rand.Rand, op Op) (Op, error) {
+	var id int64
+	if op == OpRead || op == OpUpdate || op == OpDelete {
+		var ok bool
+		if id, ok = r.ids.pick(rnd, op == OpDelete); !ok {
+			op = OpCreate
+		}
+	}
+	switch op {
+	case OpCreate:
+		n := atomic.AddInt64(&r.seq, 1)
+		id, err := r.c.Create(ctx, &v1.Foo{Title: fmt.Sprintf("loadtest %d", n), Desc: "Created by fooctl loadtest"})
+		if err == nil {
+			r.ids.add(id)
+		}
+		return op, err
+	case OpRead:
+		_, err := r.c.Get(ctx, id)
+		return op, err
+	case OpReadAll:
+		n := int32(0)
+		err := r.c.List(ctx, r.opts.PageSize, func(*v1.Foo) error {
+			if n++; n == r.opts.PageSize {
+				return errPageRead
+			}
+			return nil
+		})
+		if err == errPageRead {
+			err = nil
+		}
+		return op, err
+	case OpUpdate:
+		n := atomic.AddInt64(&r.seq, 1)
+		return op, r.c.Update(ctx, &v1.Foo{Id: id, Title: fmt.Sprintf("loadtest %d", n), Desc: "Updated by fooctl loadtest"})
+	default:
+		err := r.c.Delete(ctx, id)
+		if err != nil && client.Code(err) != codes.NotFound {
+			// Not deleted, so it may still be targeted.
+			r.ids.add(id)
+		}
+		return op, err
+	}
+}
+
+// Run seeds the Foos, warms up, then records the load for opts.Duration. It stops early,
+// reporting what was recorded, when ctx is canceled.
+func Run(ctx context.Context, c Client, opts Options) (*Report, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	r := &runner{c: c, opts: opts, picker: newPicker(opts.Mix)}
+	defer func() {
+		if opts.Cleanup {
+			r.cleanup()
+		}
+	}()
+
+	for i := 0; i < opts.Seed; i++ {
+		id, err := c.Create(ctx, &v1.Foo{Title: fmt.Sprintf("loadtest seed %d", i), Desc: "Created by fooctl loadtest"})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create seed Foo #%d: %v", i+1, err)
+		}
+		r.ids.add(id)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		start    = time.Now()
+		measured = start.Add(opts.Warmup)
+		end      = measured.Add(opts.Duration)
+		missed   int64
+		work     = make(chan time.Time, opts.Concurrency)
+		results  = make([]stats, opts.Concurrency)
+		wg       sync.WaitGroup
+	)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Until(end)):
+			cancel()
+		}
+	}()
+
+	for w := 0; w < opts.Concurrency; w++ {
+		results[w] = stats{}
+		wg.Add(1)
+		go func(s stats, seed int64) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(seed))
+			for {
+				// In open-loop mode, latency counts from when the call was due, so calls
+				// delayed by a slow server are not left out (coordinated omission).
+				due := time.Now()
+				if opts.RPS > 0 {
+					var ok bool
+					if due, ok = <-work; !ok {
+						return
+					}
+				} else if ctx.Err() != nil {
+					return
+				}
+				op, err := r.do(ctx, rnd, r.picker.pick(rnd))
+				done := time.Now()
+				if ctx.Err() != nil {
+					// Interrupted by the end of the run.
+					continue
+				}
+				if due.After(measured) {
+					s.op(op).record(done.Sub(due), err)
+				}
+			}
+		}(results[w], time.Now().UnixNano()+int64(w))
+	}
+
+	if opts.RPS > 0 {
+		interval := time.Duration(float64(time.Second) / opts.RPS)
+		next := time.Now()
+	schedule:
+		for {
+			select {
+			case <-ctx.Done():
+				break schedule
+			case <-time.After(time.Until(next)):
+			}
+			select {
+			case work <- next:
+			default:
+				if next.After(measured) {
+					// Every worker is busy: the target rate is not reached.
+					missed++
+				}
+			}
+			next = next.Add(interval)
+		}
+		close(work)
+	}
+	wg.Wait()
+
+	stopped := time.Now()
+	if stopped.After(end) {
+		stopped = end
+	}
+	if stopped.Before(measured) {
+		stopped = measured
+	}
+	total := stats{}
+	for _, s := range results {
+		for op, os := range s {
+			total.op(op).merge(os)
+		}
+	}
+	return newReport(opts, measured, stopped.Sub(measured), total, missed), nil
+}
+
+// cleanupTimeout bounds deleting the Foos left by the run, which goes on after an interrupt.
+const cleanupTimeout = time.Minute
+
+func (r *runner) cleanup() {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	for _, id := range r.ids.drain() {
+		if ctx.Err() != nil {
+			return
+		}
+		_ = r.c.Delete(ctx, id)
+	}
+}
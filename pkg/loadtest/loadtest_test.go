@@ -0,0 +1,155 @@
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+)
+
+// memoryClient keeps Foos in memory; every failEvery-th Get fails with Unavailable.
+type memoryClient struct {
+	mu        sync.Mutex
+	foos      map[int64]*v1.Foo
+	last      int64
+	gets      int
+	failEvery int
+}
+
+func (c *memoryClient) Create(ctx context.Context, foo *v1.Foo) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.last++
+	c.foos[c.last] = foo
+	return c.last, nil
+}
+
+func (c *memoryClient) Get(ctx context.Context, id int64) (*v1.Foo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gets++; c.failEvery > 0 && c.gets%c.failEvery == 0 {
+		return nil, status.Error(codes.Unavailable, "unavailable")
+	}
+	foo, ok := c.foos[id]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	return foo, nil
+}
+
+func (c *memoryClient) Update(ctx context.Context, foo *v1.Foo) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.foos[foo.Id]; !ok {
+		return status.Error(codes.NotFound, "not found")
+	}
+	c.foos[foo.Id] = foo
+	return nil
+}
+
+func (c *memoryClient) Delete(ctx context.Context, id int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.foos, id)
+	return nil
+}
+
+func (c *memoryClient) List(ctx context.Context, pageSize int32, fn func(*v1.Foo) error) error {
+	c.mu.Lock()
+	var foos []*v1.Foo
+	for _, foo := range c.foos {
+		foos = append(foos, foo)
+	}
+	c.mu.Unlock()
+	for _, foo := range foos {
+		if err := fn(foo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestParseMix(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "read=8, create=1,delete=1", want: "create=1,read=8,delete=1"},
+		{in: "READALL=1", want: "readall=1"},
+		{in: "read=0", wantErr: true},
+		{in: "list=1", wantErr: true},
+		{in: "read", wantErr: true},
+		{in: "read=-1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			m, err := ParseMix(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMix() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && m.String() != tt.want {
+				t.Errorf("ParseMix() = %s, want %s", m, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{
+			name: "01 - Closed loop",
+			opts: Options{Mix: DefaultMix, Concurrency: 4, Duration: 100 * time.Millisecond, Warmup: 20 * time.Millisecond, Seed: 10, PageSize: 5, Cleanup: true},
+		},
+		{
+			name: "02 - Open loop",
+			opts: Options{Mix: Mix{OpRead: 1}, Concurrency: 4, RPS: 500, Duration: 200 * time.Millisecond, Seed: 10, PageSize: 5, Cleanup: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &memoryClient{foos: map[int64]*v1.Foo{}, failEvery: 10}
+			r, err := Run(context.Background(), c, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Total.Requests == 0 || r.Total.P50Ms <= 0 || r.Total.P99Ms < r.Total.P50Ms {
+				t.Errorf("unexpected total %+v", r.Total)
+			}
+			// Concurrent workers may target a Foo another one just deleted.
+			if r.Total.Codes["Unavailable"] == 0 || r.Total.Codes["Unavailable"]+r.Total.Codes["NotFound"] != r.Total.Errors {
+				t.Errorf("errors = %d, codes %v, want every Unavailable Get counted", r.Total.Errors, r.Total.Codes)
+			}
+			if tt.opts.RPS > 0 {
+				// 100 calls are due; allow for slow test machines.
+				if r.Total.Requests+r.Missed < 50 || r.Total.Requests > 101 {
+					t.Errorf("made %d calls, missed %d, want about 100", r.Total.Requests, r.Missed)
+				}
+			}
+			if len(c.foos) != 0 {
+				t.Errorf("%d Foos left after cleanup", len(c.foos))
+			}
+
+			var text, csv, json bytes.Buffer
+			if err := r.WriteText(&text); err != nil || !strings.Contains(text.String(), "P99.9") || !strings.Contains(text.String(), "read Unavailable:") {
+				t.Errorf("WriteText() error = %v, wrote %s", err, text.String())
+			}
+			if err := r.WriteCSV(&csv, true); err != nil || strings.Count(csv.String(), "\n") != len(r.Ops)+2 {
+				t.Errorf("WriteCSV() error = %v, wrote %s", err, csv.String())
+			}
+			if err := r.WriteJSON(&json); err != nil || !strings.Contains(json.String(), `"p999_ms"`) {
+				t.Errorf("WriteJSON() error = %v, wrote %s", err, json.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package loadtest
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Op is a kind of call made by the load test.
+type Op string
+
+// Ops of the Foo service.
+const (
+	OpCreate  Op = "create"
+	OpRead    Op = "read"
+	OpReadAll Op = "readall"
+	OpUpdate  Op = "update"
+	OpDelete  Op = "delete"
+)
+
+// Ops lists every Op in report order.
+var Ops = []Op{OpCreate, OpRead, OpReadAll, OpUpdate, OpDelete}
+
+// Mix weighs how often each Op is made.
+type Mix map[Op]int
+
+// DefaultMix is a read-heavy mix that keeps the number of Foos roughly stable.
+var DefaultMix = Mix{OpCreate: 10, OpRead: 60, OpReadAll: 10, OpUpdate: 10, OpDelete: 10}
+
+// ParseMix parses weights such as "create=1,read=8,delete=1"; Ops not listed are not made.
+func ParseMix(s string) (Mix, error) {
+	m := Mix{}
+	for _, kv := range strings.Split(s, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		i := strings.Index(kv, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid mix entry '%s', want op=weight", kv)
+		}
+		op := Op(strings.ToLower(strings.TrimSpace(kv[:i])))
+		if !op.valid() {
+			return nil, fmt.Errorf("unknown op '%s', want one of %s", op, strings.Join(opNames(), ", "))
+		}
+		w, err := strconv.Atoi(strings.TrimSpace(kv[i+1:]))
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("invalid weight '%s' of %s, want a non-negative integer", kv[i+1:], op)
+		}
+		m[op] = w
+	}
+	if m.total() == 0 {
+		return nil, fmt.Errorf("invalid mix '%s', want at least one positive weight", s)
+	}
+	return m, nil
+}
+
+func (op Op) valid() bool {
+	for _, o := range Ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+func opNames() []string {
+	names := make([]string, len(Ops))
+	for i, op := range Ops {
+		names[i] = string(op)
+	}
+	return names
+}
+
+func (m Mix) total() int {
+	n := 0
+	for _, w := range m {
+		n += w
+	}
+	return n
+}
+
+// String formats m like ParseMix reads it.
+func (m Mix) String() string {
+	var parts []string
+	for _, op := range Ops {
+		if w, ok := m[op]; ok {
+			parts = append(parts, fmt.Sprintf("%s=%d", op, w))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// picker draws Ops at random according to a Mix.
+type picker struct {
+	ops []Op
+	cum []int
+}
+
+func newPicker(m Mix) picker {
+	var p picker
+	n := 0
+	for _, op := range Ops {
+		if m[op] > 0 {
+			n += m[op]
+			p.ops = append(p.ops, op)
+			p.cum = append(p.cum, n)
+		}
+	}
+	return p
+}
+
+func (p picker) pick(r *rand.Rand) Op {
+	x := r.Intn(p.cum[len(p.cum)-1])
+	return p.ops[sort.SearchInts(p.cum, x+1)]
+}
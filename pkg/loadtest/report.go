@@ -0,0 +1,197 @@
+package loadtest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Report summarizes the recorded part of a run. Latencies are in milliseconds.
+type Report struct {
+	// Transport and Server are set by the caller, for the record.
+	Transport   string    `json:"transport,omitempty"`
+	Server      string    `json:"server,omitempty"`
+	Start       time.Time `json:"start"`
+	Mix         string    `json:"mix"`
+	Concurrency int       `json:"concurrency"`
+	TargetRPS   float64   `json:"target_rps"`
+	WarmupSecs  float64   `json:"warmup_seconds"`
+	Seconds     float64   `json:"seconds"`
+	// Missed counts the calls due in open-loop mode that found every worker busy and were
+	// not made: the server could not sustain the target rate at this concurrency.
+	Missed int64      `json:"missed"`
+	Total  OpReport   `json:"total"`
+	Ops    []OpReport `json:"ops"`
+}
+
+// OpReport summarizes the calls of one Op, or all of them.
+type OpReport struct {
+	Op       string  `json:"op"`
+	Requests int64   `json:"requests"`
+	Errors   int64   `json:"errors"`
+	RPS      float64 `json:"rps"`
+	MinMs    float64 `json:"min_ms"`
+	MeanMs   float64 `json:"mean_ms"`
+	P50Ms    float64 `json:"p50_ms"`
+	P90Ms    float64 `json:"p90_ms"`
+	P95Ms    float64 `json:"p95_ms"`
+	P99Ms    float64 `json:"p99_ms"`
+	P999Ms   float64 `json:"p999_ms"`
+	MaxMs    float64 `json:"max_ms"`
+	// Codes counts the calls by status code name, e.g. {"OK": 980, "NotFound": 20}.
+	Codes map[string]int64 `json:"codes"`
+}
+
+func ms(ns float64) float64 {
+	return ns / float64(time.Millisecond)
+}
+
+func newOpReport(op string, s *opStats, elapsed time.Duration) OpReport {
+	h := s.latency
+	r := OpReport{
+		Op:       op,
+		Requests: h.TotalCount(),
+		Errors:   h.TotalCount() - s.codes[codes.OK.String()],
+		Codes:    s.codes,
+	}
+	if r.Requests == 0 {
+		return r
+	}
+	if elapsed > 0 {
+		r.RPS = float64(r.Requests) / elapsed.Seconds()
+	}
+	r.MinMs = ms(float64(h.Min()))
+	r.MeanMs = ms(h.Mean())
+	r.P50Ms = ms(float64(h.ValueAtQuantile(50)))
+	r.P90Ms = ms(float64(h.ValueAtQuantile(90)))
+	r.P95Ms = ms(float64(h.ValueAtQuantile(95)))
+	r.P99Ms = ms(float64(h.ValueAtQuantile(99)))
+	r.P999Ms = ms(float64(h.ValueAtQuantile(99.9)))
+	r.MaxMs = ms(float64(h.Max()))
+	return r
+}
+
+func newReport(opts Options, start time.Time, elapsed time.Duration, s stats, missed int64) *Report {
+	r := &Report{
+		Start:       start,
+		Mix:         opts.Mix.String(),
+		Concurrency: opts.Concurrency,
+		TargetRPS:   opts.RPS,
+		WarmupSecs:  opts.Warmup.Seconds(),
+		Seconds:     elapsed.Seconds(),
+		Missed:      missed,
+	}
+	total := newOpStats()
+	for _, op := range Ops {
+		if os, ok := s[op]; ok {
+			total.merge(os)
+			r.Ops = append(r.Ops, newOpReport(string(op), os, elapsed))
+		}
+	}
+	r.Total = newOpReport("total", total, elapsed)
+	return r
+}
+
+// WriteJSON writes r as an indented JSON object.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+var csvHeader = []string{
+	"start", "transport", "server", "mix", "concurrency", "target_rps", "seconds", "missed",
+	"op", "requests", "errors", "rps", "min_ms", "mean_ms", "p50_ms", "p90_ms", "p95_ms", "p99_ms", "p999_ms", "max_ms", "codes",
+}
+
+func sortedCodes(codes map[string]int64) []string {
+	var names []string
+	for code := range codes {
+		names = append(names, code)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatCodes writes codes as "NotFound=3;OK=97".
+func formatCodes(codes map[string]int64) string {
+	var parts []string
+	for _, code := range sortedCodes(codes) {
+		parts = append(parts, fmt.Sprintf("%s=%d", code, codes[code]))
+	}
+	return strings.Join(parts, ";")
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 3, 64)
+}
+
+// WriteCSV writes a row per Op and one for the total, each repeating the run settings so that
+// the reports of several runs can be appended to one file and compared. header adds the header row.
+func (r *Report) WriteCSV(w io.Writer, header bool) error {
+	cw := csv.NewWriter(w)
+	if header {
+		if err := cw.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	for _, op := range append(r.Ops, r.Total) {
+		if err := cw.Write([]string{
+			r.Start.UTC().Format(time.RFC3339), r.Transport, r.Server, r.Mix,
+			strconv.Itoa(r.Concurrency), formatFloat(r.TargetRPS), formatFloat(r.Seconds), strconv.FormatInt(r.Missed, 10),
+			op.Op, strconv.FormatInt(op.Requests, 10), strconv.FormatInt(op.Errors, 10), formatFloat(op.RPS),
+			formatFloat(op.MinMs), formatFloat(op.MeanMs), formatFloat(op.P50Ms), formatFloat(op.P90Ms),
+			formatFloat(op.P95Ms), formatFloat(op.P99Ms), formatFloat(op.P999Ms), formatFloat(op.MaxMs),
+			formatCodes(op.Codes),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteText writes a summary for people: the latency table and the errors by code.
+func (r *Report) WriteText(w io.Writer) error {
+	rate := "unlimited"
+	if r.TargetRPS > 0 {
+		rate = fmt.Sprintf("%g/s", r.TargetRPS)
+	}
+	fmt.Fprintf(w, "%s %s, mix %s, concurrency %d, target rate %s, %.1fs after %.1fs warm-up\n",
+		r.Transport, r.Server, r.Mix, r.Concurrency, rate, r.Seconds, r.WarmupSecs)
+	if r.Missed > 0 {
+		fmt.Fprintf(w, "Missed %d calls: every worker was busy, raise the concurrency or lower the rate\n", r.Missed)
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "OP\tREQUESTS\tERRORS\tRPS\tMIN\tMEAN\tP50\tP90\tP95\tP99\tP99.9\tMAX\t")
+	for _, op := range append(r.Ops, r.Total) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t\n",
+			op.Op, op.Requests, op.Errors, op.RPS, op.MinMs, op.MeanMs, op.P50Ms, op.P90Ms, op.P95Ms, op.P99Ms, op.P999Ms, op.MaxMs)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "\nLatencies in milliseconds.")
+
+	if r.Total.Errors > 0 {
+		fmt.Fprintln(w, "\nErrors:")
+		for _, op := range r.Ops {
+			for _, code := range sortedCodes(op.Codes) {
+				if code != codes.OK.String() {
+					fmt.Fprintf(w, "  %s %s: %d\n", op.Op, code, op.Codes[code])
+				}
+			}
+		}
+	}
+	return nil
+}
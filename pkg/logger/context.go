@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type ctxKeyLogger int
+
+const loggerKey ctxKeyLogger = 0
+
+// NewContext returns a copy of ctx carrying l, typically Log enriched with request-scoped fields.
+func NewContext(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// FromContext returns the request-scoped logger stored in ctx, or Log if there is none,
+// with the trace and span IDs of the span active in ctx.
+func FromContext(ctx context.Context) *zap.Logger {
+	l, ok := ctx.Value(loggerKey).(*zap.Logger)
+	if !ok {
+		l = Log
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		l = l.With(
+			zap.String("trace_id", sc.TraceID().String()),
+			zap.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return l
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFromContext(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	Log = zap.New(core)
+	defer func() { Log = zap.NewNop() }()
+
+	scoped := zap.New(core).With(zap.String("request_id", "req-1"))
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		wantFields map[string]string
+	}{
+		{
+			name:       "01 - Empty context falls back to Log",
+			ctx:        context.Background(),
+			wantFields: map[string]string{},
+		},
+		{
+			name:       "02 - Request-scoped logger",
+			ctx:        NewContext(context.Background(), scoped),
+			wantFields: map[string]string{"request_id": "req-1"},
+		},
+		{
+			name: "03 - Request-scoped logger with span",
+			ctx:  trace.ContextWithSpanContext(NewContext(context.Background(), scoped), sc),
+			wantFields: map[string]string{
+				"request_id": "req-1",
+				"trace_id":   sc.TraceID().String(),
+				"span_id":    sc.SpanID().String(),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			FromContext(tt.ctx).Info("test")
+			entries := logs.TakeAll()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			got := entries[0].ContextMap()
+			if len(got) != len(tt.wantFields) {
+				t.Errorf("fields = %v, want %v", got, tt.wantFields)
+			}
+			for k, v := range tt.wantFields {
+				if got[k] != v {
+					t.Errorf("%s = %v, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
@@ -1,19 +1,61 @@
 package logger
 
 import (
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	FormatJSON = "json"
+	// FormatConsole is a human-friendly encoder for development.
+	FormatConsole = "console"
 )
 
 var (
-	Log      *zap.Logger
-	onceInit sync.Once
+	Log = zap.NewNop()
+	// Level is the global log level; changing it takes effect immediately.
+	Level         = zap.NewAtomicLevel()
+	packageLevels atomic.Value
+	onceInit      sync.Once
 )
 
+func init() {
+	packageLevels.Store(map[string]zapcore.Level{})
+}
+
+// Config selects the encoder, output, sampling and levels of the logger.
+type Config struct {
+	// Level is the global log level, see the overview below.
+	Level int
+	// PackageLevels overrides Level for the loggers returned by Named, see ParsePackageLevels.
+	PackageLevels map[string]zapcore.Level
+	// Format is FormatJSON or FormatConsole.
+	Format string
+	// File receives every log entry instead of stdout and stderr when set. It is rotated once it
+	// reaches MaxSizeMB and, if RotateInterval is positive, at that interval. Rotated files are
+	// removed after MaxAgeDays or beyond MaxBackups, zero keeping them all.
+	File           string
+	MaxSizeMB      int
+	MaxAgeDays     int
+	MaxBackups     int
+	Compress       bool
+	RotateInterval time.Duration
+	// SampleInitial and SampleThereafter sample debug logs: of the entries with the same message
+	// in each second, the first SampleInitial are logged, then every SampleThereafter-th.
+	// A zero SampleInitial disables sampling.
+	SampleInitial    int
+	SampleThereafter int
+}
+
 func customTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
 	enc.AppendString(t.Format(time.RFC3339Nano))
 }
@@ -27,34 +69,228 @@ func customTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
 // 4: PanicLevel logs a message, then panics.
 // 5: FatalLevel logs a message, then calls os.Exit(1).
 
-func Init(level int) error {
+// Init builds Log from cfg. The returned function flushes buffered entries and closes the log file.
+func Init(cfg Config) (func() error, error) {
+	closeFn := func() error { return nil }
 	var err error
 
 	onceInit.Do(func() {
-		globalLevel := zapcore.Level(level)
+		Level.SetLevel(zapcore.Level(cfg.Level))
+		SetPackageLevels(cfg.PackageLevels)
+
+		var encoder zapcore.Encoder
+		switch cfg.Format {
+		case FormatJSON, "":
+			ecfg := zap.NewProductionEncoderConfig()
+			ecfg.EncodeTime = customTimeEncoder
+			encoder = zapcore.NewJSONEncoder(ecfg)
+		case FormatConsole:
+			ecfg := zap.NewDevelopmentEncoderConfig()
+			ecfg.EncodeTime = customTimeEncoder
+			encoder = zapcore.NewConsoleEncoder(ecfg)
+		default:
+			err = fmt.Errorf("unknown log format '%s'", cfg.Format)
+			return
+		}
 
 		highPriority := zap.LevelEnablerFunc(func(level zapcore.Level) bool {
 			return level >= zapcore.ErrorLevel
 		})
 		lowPriority := zap.LevelEnablerFunc(func(level zapcore.Level) bool {
-			return level >= globalLevel && level < zapcore.ErrorLevel
+			return level < zapcore.ErrorLevel
 		})
 
-		ecfg := zap.NewProductionEncoderConfig()
-		ecfg.EncodeTime = customTimeEncoder
-
-		consoleInfos := zapcore.Lock(os.Stdout)
-		consoleErrors := zapcore.Lock(os.Stderr)
-		consoleEncoder := zapcore.NewJSONEncoder(ecfg)
+		var core zapcore.Core
+		if len(cfg.File) == 0 {
+			core = zapcore.NewTee(
+				zapcore.NewCore(encoder, zapcore.Lock(os.Stderr), highPriority),
+				zapcore.NewCore(encoder, zapcore.Lock(os.Stdout), lowPriority),
+			)
+		} else {
+			w := &lumberjack.Logger{
+				Filename:   cfg.File,
+				MaxSize:    cfg.MaxSizeMB,
+				MaxAge:     cfg.MaxAgeDays,
+				MaxBackups: cfg.MaxBackups,
+				Compress:   cfg.Compress,
+			}
+			core = zapcore.NewCore(encoder, zapcore.AddSync(w), zapcore.DebugLevel)
+			closeFn = rotateEvery(w, cfg.RotateInterval)
+		}
 
-		core := zapcore.NewTee(
-			zapcore.NewCore(consoleEncoder, consoleErrors, highPriority),
-			zapcore.NewCore(consoleEncoder, consoleInfos, lowPriority),
-		)
+		if cfg.SampleInitial > 0 {
+			debug := zap.LevelEnablerFunc(func(level zapcore.Level) bool {
+				return level < zapcore.InfoLevel
+			})
+			other := zap.LevelEnablerFunc(func(level zapcore.Level) bool {
+				return level >= zapcore.InfoLevel
+			})
+			core = zapcore.NewTee(
+				zapcore.NewSamplerWithOptions(filterCore{core, debug}, time.Second, cfg.SampleInitial, cfg.SampleThereafter),
+				filterCore{core, other},
+			)
+		}
 
-		Log = zap.New(core)
+		Log = zap.New(levelCore{core})
 		zap.RedirectStdLog(Log)
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	closeLog := closeFn
+	return func() error {
+		_ = Log.Sync()
+		return closeLog()
+	}, nil
+}
+
+// rotateEvery rotates w at the given interval, if positive, until the returned function closes it.
+func rotateEvery(w *lumberjack.Logger, interval time.Duration) func() error {
+	if interval <= 0 {
+		return w.Close
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-t.C:
+				_ = w.Rotate()
+			}
+		}
+	}()
+	return func() error {
+		close(stop)
+		<-done
+		return w.Close()
+	}
+}
+
+// SetLevel changes the global log level of the running logger.
+func SetLevel(level int) {
+	Level.SetLevel(zapcore.Level(level))
+}
+
+// SetPackageLevels replaces the per-package levels of the running logger.
+func SetPackageLevels(levels map[string]zapcore.Level) {
+	m := make(map[string]zapcore.Level, len(levels))
+	for name, l := range levels {
+		m[name] = l
+	}
+	packageLevels.Store(m)
+}
+
+// PackageLevels returns a copy of the per-package levels of the running logger.
+func PackageLevels() map[string]zapcore.Level {
+	levels := packageLevels.Load().(map[string]zapcore.Level)
+	m := make(map[string]zapcore.Level, len(levels))
+	for name, l := range levels {
+		m[name] = l
+	}
+	return m
+}
+
+// ParsePackageLevels parses a list such as "grpc=-1,rest=warn" into per-package levels.
+// Levels are given as numbers, like the global level, or names.
+func ParsePackageLevels(s string) (map[string]zapcore.Level, error) {
+	levels := map[string]zapcore.Level{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 || len(strings.TrimSpace(kv[0])) == 0 {
+			return nil, fmt.Errorf("invalid package level '%s', expected PACKAGE=LEVEL", item)
+		}
+		name, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		var l zapcore.Level
+		if n, err := strconv.Atoi(value); err == nil {
+			l = zapcore.Level(n)
+		} else if err := l.UnmarshalText([]byte(value)); err != nil {
+			return nil, fmt.Errorf("invalid level '%s' for package '%s'", value, name)
+		}
+		if l < zapcore.DebugLevel || l > zapcore.FatalLevel {
+			return nil, fmt.Errorf("invalid level '%s' for package '%s'", value, name)
+		}
+		levels[name] = l
+	}
+	return levels, nil
+}
+
+// Named returns Log named after a package, so that its level can be set separately.
+func Named(name string) *zap.Logger {
+	return Log.Named(name)
+}
+
+// levelFor returns the level of the logger with the given name: the level of the longest
+// matching package, e.g. "grpc" for "grpc.service", or the global level.
+func levelFor(name string) zapcore.LevelEnabler {
+	levels := packageLevels.Load().(map[string]zapcore.Level)
+	for len(name) > 0 {
+		if l, ok := levels[name]; ok {
+			return l
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return Level
+}
+
+// levelCore filters entries by the global or per-package level.
+type levelCore struct {
+	zapcore.Core
+}
+
+func (c levelCore) Enabled(level zapcore.Level) bool {
+	if Level.Enabled(level) {
+		return true
+	}
+	for _, l := range packageLevels.Load().(map[string]zapcore.Level) {
+		if l.Enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return levelCore{c.Core.With(fields)}
+}
+
+func (c levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !levelFor(ent.LoggerName).Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// filterCore restricts a core to the levels of enab.
+type filterCore struct {
+	zapcore.Core
+	enab zapcore.LevelEnabler
+}
+
+func (c filterCore) Enabled(level zapcore.Level) bool {
+	return c.enab.Enabled(level) && c.Core.Enabled(level)
+}
+
+func (c filterCore) With(fields []zapcore.Field) zapcore.Core {
+	return filterCore{c.Core.With(fields), c.enab}
+}
 
-	return err
+func (c filterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.enab.Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
 }
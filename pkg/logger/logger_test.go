@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestParsePackageLevels(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    map[string]zapcore.Level
+		wantErr bool
+	}{
+		{
+			name: "01 - Empty",
+			s:    "",
+			want: map[string]zapcore.Level{},
+		},
+		{
+			name: "02 - Numbers and names",
+			s:    "grpc=-1, rest=warn",
+			want: map[string]zapcore.Level{"grpc": zapcore.DebugLevel, "rest": zapcore.WarnLevel},
+		},
+		{
+			name:    "03 - Missing level",
+			s:       "grpc",
+			wantErr: true,
+		},
+		{
+			name:    "04 - Out of range",
+			s:       "grpc=7",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePackageLevels(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePackageLevels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParsePackageLevels() = %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("%s = %v, want %v", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestLevelCore(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := zap.New(levelCore{core})
+	Level.SetLevel(zapcore.WarnLevel)
+	SetPackageLevels(map[string]zapcore.Level{"grpc": zapcore.DebugLevel, "grpc.noisy": zapcore.ErrorLevel})
+	defer func() {
+		Level.SetLevel(zapcore.InfoLevel)
+		SetPackageLevels(nil)
+	}()
+
+	l.Info("global")
+	l.Named("rest").Warn("rest")
+	l.Named("grpc").Debug("grpc")
+	l.Named("grpc").Named("service").Debug("grpc.service")
+	l.Named("grpc").Named("noisy").Warn("grpc.noisy")
+
+	var got []string
+	for _, e := range logs.All() {
+		got = append(got, e.Message)
+	}
+	want := []string{"rest", "grpc", "grpc.service"}
+	if len(got) != len(want) {
+		t.Fatalf("logged %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("logged %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "foo"
+
+var (
+	// FoosCreated counts Foo records inserted through the API.
+	FoosCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "foos_created_total",
+		Help:      "Total number of Foo records created.",
+	})
+
+	// FoosUpdated counts Foo records updated through the API.
+	FoosUpdated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "foos_updated_total",
+		Help:      "Total number of Foo records updated.",
+	})
+
+	// FoosDeleted counts Foo records deleted through the API.
+	FoosDeleted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "foos_deleted_total",
+		Help:      "Total number of Foo records deleted.",
+	})
+
+	// Panics counts handler panics recovered by the gRPC interceptors and REST middleware.
+	Panics = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "panics_recovered_total",
+		Help:      "Total number of panics recovered while handling requests.",
+	}, []string{"protocol"})
+
+	// HTTPRequests counts gateway requests by method, route and status code.
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Total number of HTTP requests handled by the gateway.",
+	}, []string{"method", "route", "code"})
+
+	// HTTPRequestDuration observes gateway latency by method, route and status code.
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests handled by the gateway.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "code"})
+)
+
+// RegisterDB exports sql.DBStats connection pool gauges for db.
+func RegisterDB(db *sql.DB, name string) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, name))
+}
@@ -0,0 +1,245 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/codes"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/audit"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/buildinfo"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/logger"
+)
+
+// Options configures the admin server.
+type Options struct {
+	// Bind is the interface address to listen on, e.g. 127.0.0.1; empty binds every interface.
+	Bind string
+	// Token, if set, must be sent as "Authorization: Bearer <token>" on every endpoint but /metrics.
+	Token string
+	// Config returns the effective configuration with secrets redacted.
+	Config func() map[string]string
+	// Audit, if set, records every change made through the admin endpoints.
+	Audit audit.Recorder
+}
+
+// Server is the operator-facing HTTP listener, kept off the public port.
+type Server struct {
+	srv     *http.Server
+	options Options
+}
+
+func NewServer(port string, options Options) *Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	protected := http.NewServeMux()
+	protected.HandleFunc("/admin/loglevel", logLevel)
+	protected.HandleFunc("/admin/buildinfo", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, buildinfo.Get())
+	})
+	protected.HandleFunc("/admin/config", func(w http.ResponseWriter, r *http.Request) {
+		if options.Config == nil {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, options.Config())
+	})
+	protected.HandleFunc("/debug/pprof/", pprof.Index)
+	// pprof.Cmdline is left out: the command line can hold secrets such as -db-password.
+	protected.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	protected.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	protected.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	auth := requireToken(options.Token, protected)
+	if options.Audit != nil {
+		auth = auditChanges(options.Audit, options.Token, auth)
+	}
+	mux.Handle("/admin/", auth)
+	mux.Handle("/debug/pprof/", auth)
+
+	return &Server{
+		srv: &http.Server{
+			Addr:    net.JoinHostPort(options.Bind, port),
+			Handler: mux,
+		},
+		options: options,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+// levels is the body of /admin/loglevel. Zap's names are used for levels, e.g. "debug".
+type levels struct {
+	Level    *zapcore.Level           `json:"level,omitempty"`
+	Packages map[string]zapcore.Level `json:"packages,omitempty"`
+}
+
+// logLevel reports the global and per-package log levels on GET. PUT changes the global
+// level if "level" is given and replaces the per-package levels if "packages" is given.
+func logLevel(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req levels
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid levels: %v", err), http.StatusBadRequest)
+			return
+		}
+		if req.Level != nil {
+			logger.Level.SetLevel(*req.Level)
+		}
+		if req.Packages != nil {
+			logger.SetPackageLevels(req.Packages)
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	level := logger.Level.Level()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(levels{Level: &level, Packages: logger.PackageLevels()})
+}
+
+func validToken(token string, r *http.Request) bool {
+	got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+// requireToken rejects requests without the bearer token. An empty token disables the check.
+func requireToken(token string, h http.Handler) http.Handler {
+	if len(token) == 0 {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !validToken(token, r) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// auditChanges records every request but GET and HEAD, including rejected ones, with recorder.
+func auditChanges(recorder audit.Recorder, token string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		actor := "anonymous"
+		if len(token) > 0 {
+			actor = "unauthenticated"
+			if validToken(token, r) {
+				actor = "admin"
+			}
+		}
+		peer := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(peer); err == nil {
+			peer = host
+		}
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+
+		e := audit.Entry{
+			Time:    start,
+			Actor:   actor,
+			Peer:    peer,
+			Method:  r.Method + " " + r.URL.Path,
+			Code:    statusCode(rec.status).String(),
+			Latency: time.Since(start),
+		}
+		if err := recorder.Record(r.Context(), e); err != nil {
+			logger.Log.Error("Failed to record audit entry", zap.Any("entry", e), zap.Error(err))
+		}
+	})
+}
+
+// statusCode maps an HTTP status to the gRPC code the gateway maps to it, so audit entries
+// of admin and API changes share their codes.
+func statusCode(status int) codes.Code {
+	switch {
+	case status >= 200 && status < 300:
+		return codes.OK
+	case status == http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case status == http.StatusForbidden:
+		return codes.PermissionDenied
+	case status == http.StatusNotFound:
+		return codes.NotFound
+	case status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented:
+		return codes.Unimplemented
+	case status == http.StatusConflict:
+		return codes.Aborted
+	case status == http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case status == http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case status == http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case status >= 400 && status < 500:
+		return codes.InvalidArgument
+	case status >= 500:
+		return codes.Internal
+	}
+	return codes.Unknown
+}
+
+// IsLoopback reports whether binding to host keeps the listener off the network.
+func IsLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (s *Server) Serve() error {
+	logger.Log.Info("Starting admin server...")
+	if len(s.options.Token) == 0 && !IsLoopback(s.options.Bind) {
+		return fmt.Errorf("admin endpoints would be reachable without a token; set a token or bind to a loopback address")
+	}
+	if err := s.srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	logger.Log.Warn("Shutting down admin server...")
+	return s.srv.Shutdown(ctx)
+}
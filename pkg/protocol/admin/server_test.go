@@ -0,0 +1,108 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/audit"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/logger"
+)
+
+func TestServer_Handler(t *testing.T) {
+	s := NewServer("0", Options{
+		Token:  "t0k3n",
+		Config: func() map[string]string { return map[string]string{"db-password": "<redacted>"} },
+	})
+	defer logger.Level.SetLevel(zapcore.InfoLevel)
+	defer logger.SetPackageLevels(nil)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		token    string
+		wantCode int
+		wantBody string
+	}{
+		{name: "01 - Metrics need no token", method: http.MethodGet, path: "/metrics", wantCode: http.StatusOK},
+		{name: "02 - Missing token", method: http.MethodGet, path: "/admin/buildinfo", wantCode: http.StatusUnauthorized},
+		{name: "03 - Wrong token", method: http.MethodGet, path: "/debug/pprof/", token: "nope", wantCode: http.StatusUnauthorized},
+		{name: "04 - Build info", method: http.MethodGet, path: "/admin/buildinfo", token: "t0k3n", wantCode: http.StatusOK, wantBody: `"go_version"`},
+		{name: "05 - Config", method: http.MethodGet, path: "/admin/config", token: "t0k3n", wantCode: http.StatusOK, wantBody: `"db-password": "<redacted>"`},
+		{name: "06 - Set log level", method: http.MethodPut, path: "/admin/loglevel", body: `{"level":"debug"}`, token: "t0k3n", wantCode: http.StatusOK, wantBody: `"level":"debug"`},
+		{name: "07 - pprof", method: http.MethodGet, path: "/debug/pprof/heap", token: "t0k3n", wantCode: http.StatusOK},
+		{name: "08 - pprof cmdline hidden", method: http.MethodGet, path: "/debug/pprof/cmdline", token: "t0k3n", wantCode: http.StatusNotFound},
+		{name: "09 - Set package log levels", method: http.MethodPut, path: "/admin/loglevel", body: `{"packages":{"grpc":"warn"}}`, token: "t0k3n", wantCode: http.StatusOK, wantBody: `"packages":{"grpc":"warn"}`},
+		{name: "10 - Invalid log level", method: http.MethodPut, path: "/admin/loglevel", body: `{"level":"loud"}`, token: "t0k3n", wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			s.srv.Handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status code = %d, want %d", w.Code, tt.wantCode)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("body %s does not contain %s", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+
+	if !logger.Level.Enabled(zapcore.DebugLevel) {
+		t.Error("log level was not changed to debug")
+	}
+	if got := logger.PackageLevels()["grpc"]; got != zapcore.WarnLevel {
+		t.Errorf("grpc package level = %v, want warn", got)
+	}
+}
+
+func TestServer_Serve_requiresTokenOffLoopback(t *testing.T) {
+	if err := NewServer("0", Options{Bind: "0.0.0.0"}).Serve(); err == nil {
+		t.Error("Serve() without a token on every interface should fail")
+	}
+}
+
+type fakeRecorder struct {
+	entries []audit.Entry
+}
+
+func (r *fakeRecorder) Record(ctx context.Context, e audit.Entry) error {
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+func TestServer_Handler_audit(t *testing.T) {
+	rec := &fakeRecorder{}
+	s := NewServer("0", Options{Token: "t0k3n", Audit: rec})
+	defer logger.Level.SetLevel(zapcore.InfoLevel)
+
+	for _, token := range []string{"nope", "t0k3n"} {
+		r := httptest.NewRequest(http.MethodPut, "/admin/loglevel", strings.NewReader(`{"level":"debug"}`))
+		r.RemoteAddr = "10.0.0.1:1234"
+		r.Header.Set("Authorization", "Bearer "+token)
+		r.Header.Set("X-Forwarded-For", "203.0.113.7")
+		s.srv.Handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	if len(rec.entries) != 2 {
+		t.Fatalf("recorded %d entries, want 2", len(rec.entries))
+	}
+	rejected, changed := rec.entries[0], rec.entries[1]
+	if rejected.Actor != "unauthenticated" || rejected.Code != "Unauthenticated" || rejected.Peer != "10.0.0.1" {
+		t.Errorf("unexpected rejected entry %+v", rejected)
+	}
+	if changed.Actor != "admin" || changed.Code != "OK" || changed.Method != "PUT /admin/loglevel" {
+		t.Errorf("unexpected change entry %+v", changed)
+	}
+}
@@ -0,0 +1,79 @@
+// Package cors matches browser origins against the configured allow list.
+package cors
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Origins is a set of allowed origins. The zero value and nil allow no cross-origin requests.
+type Origins struct {
+	any       bool
+	exact     map[string]bool
+	wildcards []wildcard
+}
+
+// wildcard matches any subdomain of suffix served over scheme, e.g. "https://*.example.com".
+type wildcard struct {
+	scheme string
+	suffix string
+}
+
+// ParseOrigins parses a comma-separated list of origins such as
+// "https://app.example.com,https://*.example.com". A "*." host prefix matches any
+// subdomain but not the domain itself; a lone "*" allows every origin.
+func ParseOrigins(s string) (*Origins, error) {
+	o := &Origins{exact: map[string]bool{}}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if len(entry) == 0 {
+			continue
+		}
+		if entry == "*" {
+			o.any = true
+			continue
+		}
+
+		u, err := url.Parse(entry)
+		if err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 || (len(u.Path) > 0 && u.Path != "/") || len(u.RawQuery) > 0 {
+			return nil, fmt.Errorf("invalid origin '%s', want scheme://host[:port]", entry)
+		}
+		if strings.HasPrefix(u.Host, "*.") {
+			o.wildcards = append(o.wildcards, wildcard{scheme: u.Scheme, suffix: u.Host[1:]})
+			continue
+		}
+		if strings.Contains(u.Host, "*") {
+			return nil, fmt.Errorf("invalid origin '%s', wildcard must be the leftmost label", entry)
+		}
+		o.exact[u.Scheme+"://"+u.Host] = true
+	}
+	return o, nil
+}
+
+// Any reports whether every origin is allowed.
+func (o *Origins) Any() bool {
+	return o != nil && o.any
+}
+
+// Allowed reports whether a request carrying the Origin header origin may be served.
+func (o *Origins) Allowed(origin string) bool {
+	if o == nil || len(origin) == 0 {
+		return false
+	}
+	if o.any {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if o.exact[origin] {
+		return true
+	}
+	for _, w := range o.wildcards {
+		host := strings.TrimPrefix(origin, w.scheme+"://")
+		if host != origin && len(host) > len(w.suffix) && strings.HasSuffix(host, w.suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package cors
+
+import "testing"
+
+func TestOrigins_Allowed(t *testing.T) {
+	o, err := ParseOrigins("https://app.example.com, https://*.example.org, http://localhost:3000")
+	if err != nil {
+		t.Fatalf("ParseOrigins() error = %v", err)
+	}
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://app.example.com", true},
+		{"HTTPS://App.Example.com", true},
+		{"http://app.example.com", false},
+		{"https://evil.example.com", false},
+		{"https://a.example.org", true},
+		{"https://a.b.example.org", true},
+		{"https://example.org", false},
+		{"http://a.example.org", false},
+		{"https://a.example.org.evil.com", false},
+		{"http://localhost:3000", true},
+		{"http://localhost:3001", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := o.Allowed(tt.origin); got != tt.want {
+			t.Errorf("Allowed(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+
+	any, _ := ParseOrigins("*")
+	if !any.Allowed("https://anything.test") {
+		t.Error("'*' should allow every origin")
+	}
+	var none *Origins
+	if none.Allowed("https://app.example.com") {
+		t.Error("nil Origins should allow nothing")
+	}
+
+	for _, in := range []string{"app.example.com", "https://app.example.com/path", "https://app.*.com"} {
+		if _, err := ParseOrigins(in); err == nil {
+			t.Errorf("ParseOrigins(%q) expected error", in)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Package zstd registers a zstd compressor with gRPC. Import it for its side effect,
+// like google.golang.org/grpc/encoding/gzip, to accept and answer zstd-compressed calls.
+package zstd
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/encoding"
+)
+
+// Name is the grpc-encoding value of the compressor.
+const Name = "zstd"
+
+// maxWindowSize bounds the window a compressed message may ask the decoder to allocate,
+// the 8 MiB RFC 8878 recommends decoders support. The decoded size itself is bounded by
+// gRPC's receive limit.
+const maxWindowSize = 8 << 20
+
+func init() {
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		panic(err)
+	}
+	encoding.RegisterCompressor(&compressor{enc: enc})
+}
+
+// compressor uses the stateless EncodeAll, which is safe for concurrent use, so one encoder
+// serves every stream. Messages are decoded as a stream, so gRPC stops reading at its receive
+// limit before an oversized message is expanded in memory.
+type compressor struct {
+	enc *zstd.Encoder
+}
+
+func (c *compressor) Name() string {
+	return Name
+}
+
+func (c *compressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	return &writer{enc: c.enc, w: w}, nil
+}
+
+func (c *compressor) Decompress(r io.Reader) (io.Reader, error) {
+	dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderLowmem(true), zstd.WithDecoderMaxMemory(maxWindowSize))
+	if err != nil {
+		return nil, err
+	}
+	rd := &reader{dec: dec}
+	// gRPC drops the reader without reading to the end when a message exceeds its receive
+	// limit, so the decoder's goroutine is also released once the reader is collected.
+	runtime.SetFinalizer(rd, func(rd *reader) { rd.close() })
+	return rd, nil
+}
+
+// reader releases its decoder once the message is read or fails to decode.
+type reader struct {
+	dec *zstd.Decoder
+	err error
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n, err := r.dec.Read(p)
+	if err != nil {
+		r.err = err
+		r.close()
+	}
+	return n, err
+}
+
+func (r *reader) close() {
+	if r.dec != nil {
+		r.dec.Close()
+		r.dec = nil
+	}
+}
+
+// writer buffers a message and compresses it as a single frame on Close.
+type writer struct {
+	enc *zstd.Encoder
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *writer) Close() error {
+	_, err := w.w.Write(w.enc.EncodeAll(w.buf.Bytes(), nil))
+	return err
+}
@@ -0,0 +1,82 @@
+package zstd
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/encoding"
+)
+
+func TestCompressor(t *testing.T) {
+	c := encoding.GetCompressor(Name)
+	if c == nil {
+		t.Fatal("zstd compressor not registered")
+	}
+
+	msg := []byte(strings.Repeat("foo", 1000))
+	var buf bytes.Buffer
+	w, err := c.Compress(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() >= len(msg) {
+		t.Errorf("compressed size %d not smaller than %d", buf.Len(), len(msg))
+	}
+
+	r, err := c.Decompress(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, msg) {
+		t.Error("decompressed message differs from the original")
+	}
+}
+
+func TestCompressor_Decompress_stopsAtLimit(t *testing.T) {
+	c := encoding.GetCompressor(Name)
+
+	// 256 MiB of zeros compresses to a few KiB.
+	enc, err := zstd.NewWriter(nil, zstd.WithWindowSize(maxWindowSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	enc.Reset(&buf)
+	zeros := make([]byte, 1<<20)
+	for i := 0; i < 256; i++ {
+		if _, err := enc.Write(zeros); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := c.Decompress(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// gRPC reads at most its receive limit plus one byte.
+	const limit = 4 << 20
+	got, err := ioutil.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != limit+1 {
+		t.Errorf("read %d bytes, want %d", len(got), limit+1)
+	}
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/audit"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/logger"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/requestid"
+)
+
+// TenantHeader is the metadata key naming the caller's tenant, recorded in the audit trail.
+const TenantHeader = "x-tenant-id"
+
+// auditRecordTimeout bounds writing an entry, which outlives the call's own deadline.
+const auditRecordTimeout = 5 * time.Second
+
+// auditedMethods are the mutating and administrative calls recorded by AddAudit.
+var auditedMethods = map[string]bool{
+	"/v1.FooService/Create":   true,
+	"/v1.FooService/Update":   true,
+	"/v1.FooService/Delete":   true,
+	"/v1.AuditService/Search": true,
+}
+
+// Actor identifies the caller in the audit trail: the authenticated user, else a hash of the
+// API key, which must not be stored itself, else "anonymous".
+func Actor(ctx context.Context) string {
+	if user := authenticatedUser(ctx); len(user) > 0 {
+		return user
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if keys := md.Get(APIKeyHeader); len(keys) > 0 && keys[0] != "" {
+		sum := sha256.Sum256([]byte(keys[0]))
+		return "key:" + hex.EncodeToString(sum[:8])
+	}
+	return "anonymous"
+}
+
+// tenant returns the tenant named by the caller; it follows the same rules as request IDs.
+func tenant(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if t := md.Get(TenantHeader); len(t) > 0 && requestid.Valid(t[0]) {
+		return t[0]
+	}
+	return ""
+}
+
+// auditTarget returns the ID of the Foo a call targeted, 0 if none.
+func auditTarget(req, resp interface{}) int64 {
+	switch r := req.(type) {
+	case *v1.UpdateRequest:
+		return r.GetFoo().GetId()
+	case *v1.DeleteRequest:
+		return r.GetId()
+	}
+	if r, ok := resp.(*v1.CreateResponse); ok {
+		return r.GetId()
+	}
+	return 0
+}
+
+func auditUnaryServerInterceptor(recorder audit.Recorder) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !auditedMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		e := audit.Entry{
+			Time:      start,
+			Actor:     Actor(ctx),
+			Tenant:    tenant(ctx),
+			Peer:      peerAddr(ctx),
+			RequestID: requestid.FromContext(ctx),
+			Method:    info.FullMethod,
+			FooID:     auditTarget(req, resp),
+			Code:      status.Code(err).String(),
+			Latency:   time.Since(start),
+		}
+
+		rctx, cancel := context.WithTimeout(context.Background(), auditRecordTimeout)
+		defer cancel()
+		if rerr := recorder.Record(rctx, e); rerr != nil {
+			logger.FromContext(ctx).Error("Failed to record audit entry", zap.Any("entry", e), zap.Error(rerr))
+		}
+		return resp, err
+	}
+}
+
+// AddAudit records every mutating and administrative call with recorder. It must run
+// after AddLogging, which assigns the request ID.
+func AddAudit(recorder audit.Recorder, opts []grpc.ServerOption) []grpc.ServerOption {
+	return append(opts, grpc.ChainUnaryInterceptor(auditUnaryServerInterceptor(recorder)))
+}
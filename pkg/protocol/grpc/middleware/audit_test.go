@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/apierrors"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/audit"
+)
+
+type fakeRecorder struct {
+	entries []audit.Entry
+}
+
+func (r *fakeRecorder) Record(ctx context.Context, e audit.Entry) error {
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+func TestAuditUnaryServerInterceptor(t *testing.T) {
+	rec := &fakeRecorder{}
+	interceptor := auditUnaryServerInterceptor(rec)
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(APIKeyHeader, "secret", TenantHeader, "acme"))
+
+	created := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &v1.CreateResponse{Id: 7}, nil
+	}
+	missing := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, apierrors.NotFound("Foo", "9")
+	}
+
+	if _, err := interceptor(ctx, &v1.ReadRequest{Id: 1}, &grpc.UnaryServerInfo{FullMethod: "/v1.FooService/Read"}, created); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := interceptor(ctx, &v1.CreateRequest{}, &grpc.UnaryServerInfo{FullMethod: "/v1.FooService/Create"}, created); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := interceptor(ctx, &v1.DeleteRequest{Id: 9}, &grpc.UnaryServerInfo{FullMethod: "/v1.FooService/Delete"}, missing); err == nil {
+		t.Fatal("expected the handler error")
+	}
+
+	if len(rec.entries) != 2 {
+		t.Fatalf("recorded %d entries, want 2 (reads are not audited)", len(rec.entries))
+	}
+	create, del := rec.entries[0], rec.entries[1]
+	if create.FooID != 7 || create.Code != "OK" || create.Tenant != "acme" || create.Peer != "10.0.0.1" {
+		t.Errorf("unexpected Create entry %+v", create)
+	}
+	if create.Actor != Actor(ctx) || create.Actor == "key:secret" {
+		t.Errorf("Actor = %q, want a hash of the API key", create.Actor)
+	}
+	if del.FooID != 9 || del.Code != "NotFound" {
+		t.Errorf("unexpected Delete entry %+v", del)
+	}
+}
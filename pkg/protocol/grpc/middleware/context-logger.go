@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/logger"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/requestid"
+)
+
+// UserMetadataKey carries the user authenticated by the HTTP gateway. It is only trusted on
+// in-process calls; the gateway drops it from client requests.
+const UserMetadataKey = "x-authenticated-user"
+
+// authenticatedUser returns the common name of a verified TLS client certificate, or the user
+// forwarded by the HTTP gateway, or "" for anonymous callers.
+func authenticatedUser(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		if chains := info.State.VerifiedChains; len(chains) > 0 && len(chains[0]) > 0 {
+			return chains[0][0].Subject.CommonName
+		}
+	}
+	if p.Addr != nil && p.Addr.Network() == InProcessNetwork {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if users := md.Get(UserMetadataKey); len(users) > 0 {
+			return users[0]
+		}
+	}
+	return ""
+}
+
+// withContextLogger stores l, enriched with the request ID, method and user, in ctx for
+// logger.FromContext. It must run after the request ID interceptor.
+func withContextLogger(ctx context.Context, l *zap.Logger, method string) context.Context {
+	fields := []zap.Field{
+		zap.String("request_id", requestid.FromContext(ctx)),
+		zap.String("grpc.method", method),
+	}
+	if user := authenticatedUser(ctx); len(user) > 0 {
+		fields = append(fields, zap.String("user", user))
+	}
+	return logger.NewContext(ctx, l.With(fields...))
+}
+
+func contextLoggerUnaryServerInterceptor(l *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withContextLogger(ctx, l, info.FullMethod), req)
+	}
+}
+
+func contextLoggerStreamServerInterceptor(l *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		wrapped := grpc_middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = withContextLogger(ss.Context(), l, info.FullMethod)
+		return handler(srv, wrapped)
+	}
+}
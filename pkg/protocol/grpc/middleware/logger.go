@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"github.com/grpc-ecosystem/go-grpc-middleware"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
 	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
 	"go.uber.org/zap"
@@ -24,14 +23,18 @@ func AddLogging(logger *zap.Logger, opts []grpc.ServerOption) []grpc.ServerOptio
 	grpc_zap.ReplaceGrpcLogger(logger)
 
 	// Unary Interceptor
-	opts = append(opts, grpc_middleware.WithUnaryServerChain(
+	opts = append(opts, grpc.ChainUnaryInterceptor(
 		grpc_ctxtags.UnaryServerInterceptor(grpc_ctxtags.WithFieldExtractor(grpc_ctxtags.CodeGenRequestFieldExtractor)),
+		requestIDUnaryServerInterceptor(),
+		contextLoggerUnaryServerInterceptor(logger),
 		grpc_zap.UnaryServerInterceptor(logger, o...),
 	))
 
 	// Stream Interceptor
-	opts = append(opts, grpc_middleware.WithStreamServerChain(
+	opts = append(opts, grpc.ChainStreamInterceptor(
 		grpc_ctxtags.StreamServerInterceptor(grpc_ctxtags.WithFieldExtractor(grpc_ctxtags.CodeGenRequestFieldExtractor)),
+		requestIDStreamServerInterceptor(),
+		contextLoggerStreamServerInterceptor(logger),
 		grpc_zap.StreamServerInterceptor(logger, o...),
 	))
 
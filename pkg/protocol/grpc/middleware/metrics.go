@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"google.golang.org/grpc"
+)
+
+// AddMetrics records per-method request counts and handling time histograms by status code.
+// Call InitializeMetrics once services are registered so every method is exported from the start.
+func AddMetrics(opts []grpc.ServerOption) []grpc.ServerOption {
+	grpc_prometheus.EnableHandlingTimeHistogram()
+
+	opts = append(opts, grpc.ChainUnaryInterceptor(grpc_prometheus.UnaryServerInterceptor))
+	opts = append(opts, grpc.ChainStreamInterceptor(grpc_prometheus.StreamServerInterceptor))
+	return opts
+}
+
+func InitializeMetrics(server *grpc.Server) {
+	grpc_prometheus.Register(server)
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/logger"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/payload"
+)
+
+func payloadField(p *payload.Policy, key string, m interface{}) zap.Field {
+	if msg, ok := m.(proto.Message); ok {
+		return zap.String(key, p.Message(msg))
+	}
+	return zap.Skip()
+}
+
+func payloadUnaryServerInterceptor(policy *payload.ReloadablePolicy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		p := policy.Load()
+		if inProcess(ctx) || !p.Sampled() {
+			return handler(ctx, req)
+		}
+		resp, err := handler(ctx, req)
+		fields := []zap.Field{payloadField(p, "grpc.request", req), zap.String("grpc.code", status.Code(err).String())}
+		if err == nil {
+			fields = append(fields, payloadField(p, "grpc.response", resp))
+		}
+		logger.FromContext(ctx).Named("payload").Info("Payload", fields...)
+		return resp, err
+	}
+}
+
+// payloadServerStream logs every message received and sent on a sampled stream.
+type payloadServerStream struct {
+	grpc.ServerStream
+	policy *payload.Policy
+}
+
+func (s *payloadServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		logger.FromContext(s.Context()).Named("payload").Info("Payload received", payloadField(s.policy, "grpc.request", m))
+	}
+	return err
+}
+
+func (s *payloadServerStream) SendMsg(m interface{}) error {
+	logger.FromContext(s.Context()).Named("payload").Info("Payload sent", payloadField(s.policy, "grpc.response", m))
+	return s.ServerStream.SendMsg(m)
+}
+
+func payloadStreamServerInterceptor(policy *payload.ReloadablePolicy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		p := policy.Load()
+		if inProcess(ss.Context()) || !p.Sampled() {
+			return handler(srv, ss)
+		}
+		return handler(srv, &payloadServerStream{ServerStream: ss, policy: p})
+	}
+}
+
+// AddPayloadLogging logs the redacted request and response messages of a sample of calls,
+// as configured by policy. Calls from the HTTP gateway are skipped, since the gateway logs
+// their payloads. It must run after AddLogging to log with the request-scoped logger.
+func AddPayloadLogging(policy *payload.ReloadablePolicy, opts []grpc.ServerOption) []grpc.ServerOption {
+	opts = append(opts, grpc.ChainUnaryInterceptor(payloadUnaryServerInterceptor(policy)))
+	opts = append(opts, grpc.ChainStreamInterceptor(payloadStreamServerInterceptor(policy)))
+	return opts
+}
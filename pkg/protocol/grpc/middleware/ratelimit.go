@@ -0,0 +1,385 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/apierrors"
+)
+
+const (
+	// APIKeyHeader is the metadata key identifying a caller by API key.
+	APIKeyHeader = "x-api-key"
+
+	forwardedForHeader = "x-forwarded-for"
+
+	healthMethodPrefix = "/grpc.health.v1.Health/"
+
+	// InProcessNetwork is the peer address network of in-process calls from the HTTP gateway.
+	InProcessNetwork = "bufconn"
+
+	// PeerMetadataKey carries the address of the HTTP gateway's peer and ClientMetadataKey
+	// the address of its client, see Proxies.Client. They are only trusted on in-process
+	// calls; the gateway drops them from client requests.
+	PeerMetadataKey   = "x-gateway-peer"
+	ClientMetadataKey = "x-gateway-client"
+
+	bucketIdleTimeout = 10 * time.Minute
+
+	// defaultMaxBuckets bounds the memory held for callers; once reached, new callers share
+	// one bucket per method until idle buckets are swept.
+	defaultMaxBuckets = 100000
+)
+
+// RateLimit describes a token bucket: RPS tokens are added every second, up to Burst.
+// A zero RPS means unlimited.
+type RateLimit struct {
+	RPS   float64
+	Burst int
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimiter keeps one token bucket per (method, caller) pair, see CallerKey.
+type RateLimiter struct {
+	mu         sync.Mutex
+	def        RateLimit
+	methods    map[string]RateLimit
+	apiKeys    map[string]bool
+	proxies    Proxies
+	buckets    map[string]*bucket
+	maxBuckets int
+	lastSweep  time.Time
+}
+
+func NewRateLimiter(def RateLimit, methods map[string]RateLimit) *RateLimiter {
+	if methods == nil {
+		methods = map[string]RateLimit{}
+	}
+	return &RateLimiter{
+		def:        def,
+		methods:    methods,
+		apiKeys:    map[string]bool{},
+		buckets:    map[string]*bucket{},
+		maxBuckets: defaultMaxBuckets,
+		lastSweep:  time.Now(),
+	}
+}
+
+// ParseMethodLimits parses limits in the form "/v1.FooService/ReadAll=5:10,/v1.FooService/Create=20",
+// where the value is RPS[:BURST]. The burst defaults to the rounded-up RPS.
+func ParseMethodLimits(s string) (map[string]RateLimit, error) {
+	limits := map[string]RateLimit{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 || !strings.HasPrefix(kv[0], "/") {
+			return nil, fmt.Errorf("invalid method rate limit '%s': expected /package.Service/Method=RPS[:BURST]", item)
+		}
+		l, err := ParseRateLimit(kv[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid method rate limit '%s': %v", item, err)
+		}
+		limits[kv[0]] = l
+	}
+	return limits, nil
+}
+
+// ParseRateLimit parses a single RPS[:BURST] value.
+func ParseRateLimit(s string) (RateLimit, error) {
+	parts := strings.SplitN(s, ":", 2)
+	rps, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil || rps < 0 {
+		return RateLimit{}, fmt.Errorf("invalid RPS '%s'", parts[0])
+	}
+	burst := int(math.Ceil(rps))
+	if len(parts) == 2 {
+		burst, err = strconv.Atoi(parts[1])
+		if err != nil || burst < 1 {
+			return RateLimit{}, fmt.Errorf("invalid burst '%s'", parts[1])
+		}
+	}
+	return RateLimit{RPS: rps, Burst: burst}, nil
+}
+
+// Update replaces the limits, e.g. on a configuration reload. Callers start with full buckets.
+func (l *RateLimiter) Update(def RateLimit, methods map[string]RateLimit) {
+	if methods == nil {
+		methods = map[string]RateLimit{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.def = def
+	l.methods = methods
+	l.buckets = map[string]*bucket{}
+}
+
+// SetAPIKeys replaces the API keys that get a bucket of their own. Callers sending any other
+// key are limited by client address, so that random keys cannot dodge the limit.
+func (l *RateLimiter) SetAPIKeys(keys []string) {
+	m := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		m[k] = true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.apiKeys = m
+}
+
+// SetTrustedProxies replaces the proxies whose X-Forwarded-For entries identify callers.
+func (l *RateLimiter) SetTrustedProxies(p Proxies) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.proxies = p
+}
+
+// limitFor must be called with l.mu held.
+func (l *RateLimiter) limitFor(method string) RateLimit {
+	if strings.HasPrefix(method, healthMethodPrefix) {
+		return RateLimit{}
+	}
+	if ml, ok := l.methods[method]; ok {
+		return ml
+	}
+	return l.def
+}
+
+// Allow takes a token for the caller on the given method. When no token is
+// available it returns false together with the time until the next one.
+func (l *RateLimiter) Allow(method, caller string) (bool, time.Duration) {
+	now := time.Now()
+	key := method + "|" + caller
+
+	l.mu.Lock()
+	limit := l.limitFor(method)
+	if limit.RPS <= 0 {
+		l.mu.Unlock()
+		return true, 0
+	}
+	if now.Sub(l.lastSweep) > bucketIdleTimeout {
+		l.sweep(now)
+	}
+	b, ok := l.buckets[key]
+	if !ok && len(l.buckets) >= l.maxBuckets {
+		l.sweep(now)
+		if len(l.buckets) >= l.maxBuckets {
+			key = method + "|overflow"
+			b, ok = l.buckets[key]
+		}
+	}
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(limit.RPS), limit.Burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	l.mu.Unlock()
+
+	r := b.limiter.ReserveN(now, 1)
+	if !r.OK() {
+		return false, time.Second
+	}
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// sweep drops the buckets idle for bucketIdleTimeout. It must be called with l.mu held.
+func (l *RateLimiter) sweep(now time.Time) {
+	for k, b := range l.buckets {
+		if now.Sub(b.lastSeen) > bucketIdleTimeout {
+			delete(l.buckets, k)
+		}
+	}
+	l.lastSweep = now
+}
+
+// CallerKey identifies the caller by authenticated user, else by API key if it is one set by
+// SetAPIKeys, else by client IP.
+func (l *RateLimiter) CallerKey(ctx context.Context) string {
+	if user := authenticatedUser(ctx); len(user) > 0 {
+		return "user:" + user
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if keys := md.Get(APIKeyHeader); len(keys) > 0 && keys[0] != "" {
+		l.mu.Lock()
+		known := l.apiKeys[keys[0]]
+		l.mu.Unlock()
+		if known {
+			return "key:" + keys[0]
+		}
+	}
+	l.mu.Lock()
+	proxies := l.proxies
+	l.mu.Unlock()
+	return "ip:" + clientIP(ctx, proxies)
+}
+
+// Proxies are the networks of trusted reverse proxies, such as load balancers, whose
+// X-Forwarded-For entries identify the client.
+type Proxies []*net.IPNet
+
+// ParseProxies parses a comma-separated list of CIDRs or addresses, e.g. "10.0.0.0/8,127.0.0.1".
+func ParseProxies(s string) (Proxies, error) {
+	var p Proxies
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid proxy address '%s'", item)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			p = append(p, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy network '%s'", item)
+		}
+		p = append(p, n)
+	}
+	return p, nil
+}
+
+func (p Proxies) trusted(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, n := range p {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Client returns the address of the client behind host, the address of the connection's
+// peer. X-Forwarded-For is only read when host is a trusted proxy, and then from the right:
+// each proxy appends the address it received the request from, so the right-most entry that
+// is not a trusted proxy is the client. Entries further left come from the client itself.
+func (p Proxies) Client(host string, forwardedFor []string) string {
+	if !p.trusted(host) {
+		return host
+	}
+	var hops []string
+	for _, v := range forwardedFor {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	client := host
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			break
+		}
+		client = hop
+		if !p.trusted(hop) {
+			break
+		}
+	}
+	return client
+}
+
+// peerHost returns the address of the connection's peer, without the port.
+func peerHost(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host := p.Addr.String()
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return host
+}
+
+// inProcess reports whether the call comes from the HTTP gateway in the same process.
+func inProcess(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	return ok && p.Addr != nil && p.Addr.Network() == InProcessNetwork
+}
+
+// gatewayAddr returns the address the HTTP gateway forwarded under key on in-process calls.
+func gatewayAddr(ctx context.Context, key string) string {
+	if !inProcess(ctx) {
+		return ""
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if addrs := md.Get(key); len(addrs) > 0 {
+		return addrs[0]
+	}
+	return ""
+}
+
+// peerAddr returns the address of the caller's connection, the one forwarded by the HTTP
+// gateway for in-process calls. Unlike clientIP, it never trusts X-Forwarded-For.
+func peerAddr(ctx context.Context) string {
+	if addr := gatewayAddr(ctx, PeerMetadataKey); addr != "" {
+		return addr
+	}
+	return peerHost(ctx)
+}
+
+// clientIP returns the address of the caller: the one forwarded by the HTTP gateway for
+// in-process calls, else the peer's, see Proxies.Client.
+func clientIP(ctx context.Context, proxies Proxies) string {
+	if addr := gatewayAddr(ctx, ClientMetadataKey); addr != "" {
+		return addr
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	return proxies.Client(peerHost(ctx), md.Get(forwardedForHeader))
+}
+
+func rateLimitError(method string, delay time.Duration) error {
+	return apierrors.ResourceExhausted(apierrors.ReasonRateLimited,
+		fmt.Sprintf("Rate limit exceeded for %s, retry in %s", method, delay.Round(time.Millisecond)), delay)
+}
+
+func (l *RateLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if ok, delay := l.Allow(info.FullMethod, l.CallerKey(ctx)); !ok {
+			return nil, rateLimitError(info.FullMethod, delay)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (l *RateLimiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if ok, delay := l.Allow(info.FullMethod, l.CallerKey(ss.Context())); !ok {
+			return rateLimitError(info.FullMethod, delay)
+		}
+		return handler(srv, ss)
+	}
+}
+
+func AddRateLimit(limiter *RateLimiter, opts []grpc.ServerOption) []grpc.ServerOption {
+	opts = append(opts, grpc.ChainUnaryInterceptor(limiter.UnaryServerInterceptor()))
+	opts = append(opts, grpc.ChainStreamInterceptor(limiter.StreamServerInterceptor()))
+	return opts
+}
@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseMethodLimits(t *testing.T) {
+	got, err := ParseMethodLimits("/v1.FooService/ReadAll=5:10, /v1.FooService/Create=2.5")
+	if err != nil {
+		t.Fatalf("ParseMethodLimits() error = %v", err)
+	}
+	if got["/v1.FooService/ReadAll"] != (RateLimit{RPS: 5, Burst: 10}) {
+		t.Errorf("ReadAll limit = %+v", got["/v1.FooService/ReadAll"])
+	}
+	if got["/v1.FooService/Create"] != (RateLimit{RPS: 2.5, Burst: 3}) {
+		t.Errorf("Create limit = %+v", got["/v1.FooService/Create"])
+	}
+
+	for _, in := range []string{"ReadAll=5", "/v1.FooService/ReadAll", "/v1.FooService/ReadAll=x", "/v1.FooService/ReadAll=5:0"} {
+		if _, err := ParseMethodLimits(in); err == nil {
+			t.Errorf("ParseMethodLimits(%q) expected error", in)
+		}
+	}
+}
+
+func TestRateLimiter_UnaryServerInterceptor(t *testing.T) {
+	l := NewRateLimiter(RateLimit{}, map[string]RateLimit{"/v1.FooService/ReadAll": {RPS: 1, Burst: 2}})
+	l.SetAPIKeys([]string{"a", "b"})
+	interceptor := l.UnaryServerInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+
+	call := func(method, key string) error {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234}})
+		if key != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(APIKeyHeader, key))
+		}
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := call("/v1.FooService/ReadAll", "a"); err != nil {
+			t.Fatalf("call %d: unexpected error %v", i, err)
+		}
+	}
+
+	err := call("/v1.FooService/ReadAll", "a")
+	s := status.Convert(err)
+	if s.Code() != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted, got %v", err)
+	}
+	var retry *errdetails.RetryInfo
+	for _, d := range s.Details() {
+		if ri, ok := d.(*errdetails.RetryInfo); ok {
+			retry = ri
+		}
+	}
+	if retry == nil || retry.RetryDelay.AsDuration() <= 0 {
+		t.Errorf("expected RetryInfo detail, got %v", s.Details())
+	}
+
+	if err := call("/v1.FooService/ReadAll", "b"); err != nil {
+		t.Errorf("other caller should have its own bucket: %v", err)
+	}
+	if err := call("/v1.FooService/Read", "a"); err != nil {
+		t.Errorf("unlimited method should pass: %v", err)
+	}
+	if err := call("/v1.FooService/ReadAll", ""); err != nil {
+		t.Fatalf("first call by address: unexpected error %v", err)
+	}
+	if err := call("/v1.FooService/ReadAll", ""); err != nil {
+		t.Fatalf("second call by address: unexpected error %v", err)
+	}
+	if err := call("/v1.FooService/ReadAll", "random"); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("unknown key should share the address's bucket, got %v", err)
+	}
+}
+
+func TestRateLimiter_Allow_maxBuckets(t *testing.T) {
+	l := NewRateLimiter(RateLimit{RPS: 1, Burst: 1}, nil)
+	l.maxBuckets = 2
+
+	for _, caller := range []string{"ip:10.0.0.1", "ip:10.0.0.2", "ip:10.0.0.3"} {
+		if ok, _ := l.Allow("/v1.FooService/Read", caller); !ok {
+			t.Fatalf("first call by %s was limited", caller)
+		}
+	}
+	if ok, _ := l.Allow("/v1.FooService/Read", "ip:10.0.0.4"); ok {
+		t.Error("callers over the bucket cap should share a bucket")
+	}
+	if len(l.buckets) != 3 {
+		t.Errorf("kept %d buckets, want 2 and the overflow bucket", len(l.buckets))
+	}
+}
+
+func TestRateLimiter_CallerKey(t *testing.T) {
+	l := NewRateLimiter(RateLimit{}, nil)
+	l.SetAPIKeys([]string{"k"})
+	loopback, _ := ParseProxies("127.0.0.1")
+	l.SetTrustedProxies(loopback)
+	local := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1}})
+	remote := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.9"), Port: 1}})
+	fwd := metadata.Pairs(forwardedForHeader, "203.0.113.7, 10.0.0.1")
+
+	if got := l.CallerKey(metadata.NewIncomingContext(local, fwd)); got != "ip:10.0.0.1" {
+		t.Errorf("local proxy caller = %s, want the last forwarded address", got)
+	}
+	if got := l.CallerKey(metadata.NewIncomingContext(remote, fwd)); got != "ip:10.0.0.9" {
+		t.Errorf("spoofed forwarded header should be ignored, got %s", got)
+	}
+	if got := l.CallerKey(metadata.NewIncomingContext(remote, metadata.Pairs(APIKeyHeader, "k"))); got != "key:k" {
+		t.Errorf("api key caller = %s", got)
+	}
+	if got := l.CallerKey(metadata.NewIncomingContext(remote, metadata.Pairs(APIKeyHeader, "unknown"))); got != "ip:10.0.0.9" {
+		t.Errorf("unknown api key caller = %s, want the address", got)
+	}
+}
+
+func TestProxies_Client(t *testing.T) {
+	proxies, err := ParseProxies("127.0.0.1, 10.0.0.0/8")
+	if err != nil {
+		t.Fatalf("ParseProxies() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		host         string
+		forwardedFor []string
+		want         string
+	}{
+		{name: "01 - Untrusted peer", host: "192.0.2.1", forwardedFor: []string{"203.0.113.1"}, want: "192.0.2.1"},
+		{name: "02 - Local proxy", host: "127.0.0.1", forwardedFor: []string{"203.0.113.1, 192.0.2.1"}, want: "192.0.2.1"},
+		{name: "03 - Load balancer", host: "10.0.1.5", forwardedFor: []string{"203.0.113.1"}, want: "203.0.113.1"},
+		{name: "04 - Spoofed entries left of the client", host: "10.0.1.5", forwardedFor: []string{"198.51.100.1, 203.0.113.1"}, want: "203.0.113.1"},
+		{name: "05 - Chained proxies", host: "127.0.0.1", forwardedFor: []string{"203.0.113.1, 10.0.2.7", "10.0.1.5"}, want: "203.0.113.1"},
+		{name: "06 - No header", host: "10.0.1.5", want: "10.0.1.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := proxies.Client(tt.host, tt.forwardedFor); got != tt.want {
+				t.Errorf("Client() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	for _, in := range []string{"10.0.0.0/33", "proxy.local"} {
+		if _, err := ParseProxies(in); err == nil {
+			t.Errorf("ParseProxies(%q) expected error", in)
+		}
+	}
+}
+
+type inProcessAddr struct{}
+
+func (inProcessAddr) Network() string { return InProcessNetwork }
+func (inProcessAddr) String() string  { return InProcessNetwork }
+
+func Test_peerAddr(t *testing.T) {
+	local := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1}})
+	gateway := peer.NewContext(context.Background(), &peer.Peer{Addr: inProcessAddr{}})
+	fwd := metadata.Pairs(forwardedForHeader, "203.0.113.7", PeerMetadataKey, "10.0.0.1", ClientMetadataKey, "203.0.113.7")
+
+	if got := peerAddr(metadata.NewIncomingContext(local, fwd)); got != "127.0.0.1" {
+		t.Errorf("peerAddr() = %s, want the connection's peer", got)
+	}
+	if got := peerAddr(metadata.NewIncomingContext(gateway, fwd)); got != "10.0.0.1" {
+		t.Errorf("peerAddr() = %s, want the gateway's peer", got)
+	}
+	if got := clientIP(metadata.NewIncomingContext(gateway, fwd), nil); got != "203.0.113.7" {
+		t.Errorf("clientIP() = %s, want the gateway's client", got)
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/apierrors"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/metrics"
+)
+
+// recoverPanic logs the panic with its stack trace and request ID, and turns it into
+// an Internal error so the connection survives.
+func recoverPanic(ctx context.Context, p interface{}) error {
+	metrics.Panics.WithLabelValues("grpc").Inc()
+	return apierrors.Internal(ctx, apierrors.ReasonPanic, "Internal error", fmt.Errorf("panic: %v", p),
+		zap.ByteString("stack", debug.Stack()),
+	)
+}
+
+// AddRecovery must come after AddLogging so the request ID is available and the
+// resulting Internal status is logged.
+func AddRecovery(opts []grpc.ServerOption) []grpc.ServerOption {
+	o := []grpc_recovery.Option{
+		grpc_recovery.WithRecoveryHandlerContext(recoverPanic),
+	}
+
+	opts = append(opts, grpc.ChainUnaryInterceptor(grpc_recovery.UnaryServerInterceptor(o...)))
+	opts = append(opts, grpc.ChainStreamInterceptor(grpc_recovery.StreamServerInterceptor(o...)))
+	return opts
+}
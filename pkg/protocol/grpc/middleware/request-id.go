@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/requestid"
+)
+
+// requestIDFromMetadata reuses a valid x-request-id sent by the caller (the HTTP
+// gateway forwards its own) or generates one for direct gRPC callers.
+func requestIDFromMetadata(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ids := md.Get(requestid.MetadataKey); len(ids) > 0 && requestid.Valid(ids[0]) {
+		return ids[0]
+	}
+	return requestid.New()
+}
+
+// tagRequestID stores the ID in the context and in the ctxtags picked up by grpc_zap.
+func tagRequestID(ctx context.Context, id string) context.Context {
+	grpc_ctxtags.Extract(ctx).Set("request_id", id)
+	return requestid.NewContext(ctx, id)
+}
+
+func requestIDUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		id := requestIDFromMetadata(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestid.MetadataKey, id))
+		return handler(tagRequestID(ctx, id), req)
+	}
+}
+
+func requestIDStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		id := requestIDFromMetadata(ss.Context())
+		_ = ss.SetHeader(metadata.Pairs(requestid.MetadataKey, id))
+		wrapped := grpc_middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = tagRequestID(ss.Context(), id)
+		return handler(srv, wrapped)
+	}
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Timeout bounds the handling time of a call. Default applies when the client sent no
+// deadline; a client deadline further away than Max is shortened to Max. Zero disables either.
+type Timeout struct {
+	Default time.Duration
+	Max     time.Duration
+}
+
+// Timeouts holds the server-wide timeout and per-method overrides.
+type Timeouts struct {
+	Server  Timeout
+	Methods map[string]Timeout
+}
+
+// ParseMethodTimeouts parses timeouts in the form "/v1.FooService/ReadAll=5s:30s,/v1.FooService/Read=2s",
+// where the value is DEFAULT[:MAX]. MAX defaults to DEFAULT.
+func ParseMethodTimeouts(s string) (map[string]Timeout, error) {
+	timeouts := map[string]Timeout{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 || !strings.HasPrefix(kv[0], "/") {
+			return nil, fmt.Errorf("invalid method timeout '%s': expected /package.Service/Method=DEFAULT[:MAX]", item)
+		}
+		parts := strings.SplitN(kv[1], ":", 2)
+		def, err := time.ParseDuration(parts[0])
+		if err != nil || def < 0 {
+			return nil, fmt.Errorf("invalid method timeout '%s': invalid default '%s'", item, parts[0])
+		}
+		max := def
+		if len(parts) == 2 {
+			max, err = time.ParseDuration(parts[1])
+			if err != nil || max < def {
+				return nil, fmt.Errorf("invalid method timeout '%s': max must be a duration of at least the default", item)
+			}
+		}
+		timeouts[kv[0]] = Timeout{Default: def, Max: max}
+	}
+	return timeouts, nil
+}
+
+func (t Timeouts) forMethod(method string) Timeout {
+	if strings.HasPrefix(method, healthMethodPrefix) {
+		return Timeout{}
+	}
+	if mt, ok := t.Methods[method]; ok {
+		return mt
+	}
+	return t.Server
+}
+
+// withDeadline applies the method timeout to ctx. The returned cancel function must always be called.
+func (t Timeouts) withDeadline(ctx context.Context, method string) (context.Context, context.CancelFunc) {
+	mt := t.forMethod(method)
+	if deadline, ok := ctx.Deadline(); ok {
+		if mt.Max > 0 && time.Until(deadline) > mt.Max {
+			return context.WithTimeout(ctx, mt.Max)
+		}
+		return ctx, func() {}
+	}
+	if mt.Default > 0 {
+		return context.WithTimeout(ctx, mt.Default)
+	}
+	return ctx, func() {}
+}
+
+type deadlineServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *deadlineServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// ReloadableTimeouts holds Timeouts that can be replaced while the server is running.
+type ReloadableTimeouts struct {
+	v atomic.Value
+}
+
+func NewReloadableTimeouts(t Timeouts) *ReloadableTimeouts {
+	r := &ReloadableTimeouts{}
+	r.Store(t)
+	return r
+}
+
+// Store replaces the timeouts applied to calls started from now on.
+func (r *ReloadableTimeouts) Store(t Timeouts) {
+	r.v.Store(t)
+}
+
+func (r *ReloadableTimeouts) Load() Timeouts {
+	return r.v.Load().(Timeouts)
+}
+
+func (r *ReloadableTimeouts) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, cancel := r.Load().withDeadline(ctx, info.FullMethod)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+func (r *ReloadableTimeouts) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, cancel := r.Load().withDeadline(ss.Context(), info.FullMethod)
+		defer cancel()
+		return handler(srv, &deadlineServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func AddTimeouts(timeouts *ReloadableTimeouts, opts []grpc.ServerOption) []grpc.ServerOption {
+	opts = append(opts, grpc.ChainUnaryInterceptor(timeouts.UnaryServerInterceptor()))
+	opts = append(opts, grpc.ChainStreamInterceptor(timeouts.StreamServerInterceptor()))
+	return opts
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func TestTimeouts_withDeadline(t *testing.T) {
+	timeouts := Timeouts{
+		Server: Timeout{Default: time.Second, Max: 5 * time.Second},
+		Methods: map[string]Timeout{
+			"/v1.FooService/ReadAll": {Default: 10 * time.Second, Max: 20 * time.Second},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		clientDeadline time.Duration
+		want           time.Duration
+	}{
+		{name: "01 - Default applied", method: "/v1.FooService/Read", want: time.Second},
+		{name: "02 - Client deadline kept", method: "/v1.FooService/Read", clientDeadline: 3 * time.Second, want: 3 * time.Second},
+		{name: "03 - Client deadline capped", method: "/v1.FooService/Read", clientDeadline: time.Minute, want: 5 * time.Second},
+		{name: "04 - Method override", method: "/v1.FooService/ReadAll", want: 10 * time.Second},
+		{name: "05 - Method max", method: "/v1.FooService/ReadAll", clientDeadline: time.Minute, want: 20 * time.Second},
+		{name: "06 - Health exempt", method: "/grpc.health.v1.Health/Check"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.clientDeadline > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.clientDeadline)
+				defer cancel()
+			}
+
+			ctx, cancel := timeouts.withDeadline(ctx, tt.method)
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			if tt.want == 0 {
+				if ok {
+					t.Errorf("unexpected deadline in %v", time.Until(deadline))
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("expected a deadline")
+			}
+			if got := time.Until(deadline); got > tt.want || got < tt.want-time.Second {
+				t.Errorf("deadline in %v, want about %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReloadableTimeouts_UnaryServerInterceptor(t *testing.T) {
+	timeouts := NewReloadableTimeouts(Timeouts{Server: Timeout{Default: time.Second}})
+	interceptor := timeouts.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/v1.FooService/Read"}
+
+	deadline := func() time.Duration {
+		var got time.Duration
+		_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			if d, ok := ctx.Deadline(); ok {
+				got = time.Until(d)
+			}
+			return nil, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	if got := deadline(); got > time.Second || got < 0 {
+		t.Errorf("deadline in %v, want about 1s", got)
+	}
+
+	timeouts.Store(Timeouts{Server: Timeout{Default: time.Minute}})
+	if got := deadline(); got > time.Minute || got < time.Minute-time.Second {
+		t.Errorf("after reload, deadline in %v, want about 1m", got)
+	}
+
+	timeouts.Store(Timeouts{})
+	if got := deadline(); got != 0 {
+		t.Errorf("after reload, deadline in %v, want none", got)
+	}
+}
@@ -0,0 +1,14 @@
+package middleware
+
+import (
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+)
+
+// AddTracing continues the trace propagated in the W3C traceparent metadata,
+// or starts a new one, for every call. It should run before the other interceptors.
+func AddTracing(opts []grpc.ServerOption) []grpc.ServerOption {
+	opts = append(opts, grpc.ChainUnaryInterceptor(otelgrpc.UnaryServerInterceptor()))
+	opts = append(opts, grpc.ChainStreamInterceptor(otelgrpc.StreamServerInterceptor()))
+	return opts
+}
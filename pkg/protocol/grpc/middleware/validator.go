@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"google.golang.org/grpc"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/apierrors"
+)
+
+// validatorAll is implemented by messages generated by protoc-gen-validate.
+type validatorAll interface {
+	ValidateAll() error
+}
+
+// fieldError and multiError match the error types generated by protoc-gen-validate.
+type fieldError interface {
+	Field() string
+	Reason() string
+	Cause() error
+}
+
+type multiError interface {
+	AllErrors() []error
+}
+
+// protoFieldName converts a generated Go field name such as SysFields to sys_fields.
+func protoFieldName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// fieldViolations flattens nested validation errors into violations with
+// dotted field paths, e.g. foo.title.
+func fieldViolations(path string, err error) []apierrors.FieldViolation {
+	if me, ok := err.(multiError); ok {
+		var out []apierrors.FieldViolation
+		for _, e := range me.AllErrors() {
+			out = append(out, fieldViolations(path, e)...)
+		}
+		return out
+	}
+	if fe, ok := err.(fieldError); ok {
+		field := protoFieldName(fe.Field())
+		if path != "" {
+			field = path + "." + field
+		}
+		if fe.Cause() != nil {
+			return fieldViolations(field, fe.Cause())
+		}
+		return []apierrors.FieldViolation{{Field: field, Description: fe.Reason()}}
+	}
+	return []apierrors.FieldViolation{{Field: path, Description: err.Error()}}
+}
+
+func validate(req interface{}) error {
+	v, ok := req.(validatorAll)
+	if !ok {
+		return nil
+	}
+	if err := v.ValidateAll(); err != nil {
+		return apierrors.InvalidArgument("Request failed validation", fieldViolations("", err)...)
+	}
+	return nil
+}
+
+func validatorUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := validate(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+type validatingServerStream struct {
+	grpc.ServerStream
+}
+
+func (s *validatingServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validate(m)
+}
+
+func validatorStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingServerStream{ServerStream: ss})
+	}
+}
+
+// AddValidation rejects requests breaking the rules declared on the proto messages
+// with InvalidArgument and a BadRequest detail listing every field violation.
+func AddValidation(opts []grpc.ServerOption) []grpc.ServerOption {
+	opts = append(opts, grpc.ChainUnaryInterceptor(validatorUnaryServerInterceptor()))
+	opts = append(opts, grpc.ChainStreamInterceptor(validatorStreamServerInterceptor()))
+	return opts
+}
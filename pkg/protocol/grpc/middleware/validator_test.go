@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+)
+
+func Test_validatorUnaryServerInterceptor(t *testing.T) {
+	interceptor := validatorUnaryServerInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+
+	tests := []struct {
+		name           string
+		req            interface{}
+		wantViolations []string
+	}{
+		{
+			name: "01 - OK",
+			req:  &v1.CreateRequest{ApiVersion: "v1", Foo: &v1.Foo{Title: "title"}},
+		},
+		{
+			name:           "02 - Missing foo",
+			req:            &v1.CreateRequest{ApiVersion: "v1"},
+			wantViolations: []string{"foo"},
+		},
+		{
+			name: "03 - Nested field violations",
+			req: &v1.UpdateRequest{ApiVersion: "v1", Foo: &v1.Foo{
+				Title:     " ",
+				Desc:      strings.Repeat("d", 1025),
+				SysFields: &v1.SystemFields{UpdatedBy: strings.Repeat("u", 1025)},
+			}},
+			wantViolations: []string{"foo.title", "foo.desc", "foo.sys_fields.updated_by"},
+		},
+		{
+			name:           "04 - Invalid id",
+			req:            &v1.ReadRequest{ApiVersion: "v1"},
+			wantViolations: []string{"id"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := interceptor(context.Background(), tt.req, &grpc.UnaryServerInfo{FullMethod: "/v1.FooService/Test"}, handler)
+			if tt.wantViolations == nil {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				return
+			}
+
+			s := status.Convert(err)
+			if s.Code() != codes.InvalidArgument {
+				t.Fatalf("expected InvalidArgument, got %v", err)
+			}
+			var got []string
+			for _, d := range s.Details() {
+				if br, ok := d.(*errdetails.BadRequest); ok {
+					for _, v := range br.FieldViolations {
+						got = append(got, v.Field)
+					}
+				}
+			}
+			if !reflect.DeepEqual(got, tt.wantViolations) {
+				t.Errorf("violations = %v, want %v", got, tt.wantViolations)
+			}
+		})
+	}
+}
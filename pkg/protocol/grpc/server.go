@@ -3,37 +3,224 @@ package grpc
 import (
 	"context"
 	"net"
-	"os"
-	"os/signal"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
 
 	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/audit"
 	"github.com/wingkwong/go-grpc-boilerplate/pkg/logger"
 	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/grpc/middleware"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/payload"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/test/bufconn"
+
+	// Registered compressors answer clients that send grpc-encoding gzip or zstd.
+	_ "github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/grpc/encoding/zstd"
+	_ "google.golang.org/grpc/encoding/gzip"
 )
 
-func RunServer(ctx context.Context, v1API v1.FooServiceServer, port string) error {
-	listen, err := net.Listen("tcp", ":"+port)
-	if err != nil {
-		return err
+// InProcessNetwork is the peer address network of calls made through DialInProcess.
+const InProcessNetwork = middleware.InProcessNetwork
+
+const inProcessBufferSize = 1024 * 1024
+
+// Options configures the interceptors and transport limits installed by NewServer.
+// Zero limits keep the gRPC defaults.
+type Options struct {
+	// RateLimiter is optional; nil disables rate limiting.
+	RateLimiter *middleware.RateLimiter
+	// Timeouts is optional; nil applies no deadlines.
+	Timeouts *middleware.ReloadableTimeouts
+	// Payloads is optional; nil disables payload logging.
+	Payloads *payload.ReloadablePolicy
+	// Audit is optional; nil records no audit trail.
+	Audit audit.Recorder
+	// AuditAPI is registered if set.
+	AuditAPI v1.AuditServiceServer
+
+	MaxRecvMsgSize       int
+	MaxSendMsgSize       int
+	MaxConcurrentStreams uint32
+	// KeepaliveMinTime is the shortest client ping interval tolerated before the
+	// connection is closed with ENHANCE_YOUR_CALM.
+	KeepaliveMinTime time.Duration
+	// KeepalivePermitWithoutStream allows client pings on connections with no active RPC.
+	KeepalivePermitWithoutStream bool
+}
+
+func (o Options) serverOptions() []grpc.ServerOption {
+	var opts []grpc.ServerOption
+	if o.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(o.MaxRecvMsgSize))
+	}
+	if o.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(o.MaxSendMsgSize))
+	}
+	if o.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(o.MaxConcurrentStreams))
 	}
+	if o.KeepaliveMinTime > 0 || o.KeepalivePermitWithoutStream {
+		opts = append(opts, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             o.KeepaliveMinTime,
+			PermitWithoutStream: o.KeepalivePermitWithoutStream,
+		}))
+	}
+	return opts
+}
 
-	opts := []grpc.ServerOption{}
-	opts = middleware.AddLogging(logger.Log, opts)
+type Server struct {
+	options      Options
+	server       *grpc.Server
+	healthServer *health.Server
+	// listener is nil when gRPC is only served through ServeHTTP.
+	listener  net.Listener
+	inProcess *bufconn.Listener
+}
+
+// NewServer creates the gRPC server and listens on port. With an empty port the server
+// has no TCP listener of its own and is reached through ServeHTTP and DialInProcess.
+func NewServer(v1API v1.FooServiceServer, port string, options Options) (*Server, error) {
+	var listen net.Listener
+	if len(port) > 0 {
+		l, err := net.Listen("tcp", ":"+port)
+		if err != nil {
+			return nil, err
+		}
+		listen = l
+	}
+
+	opts := options.serverOptions()
+	opts = middleware.AddTracing(opts)
+	opts = middleware.AddLogging(logger.Named("grpc"), opts)
+	if options.Payloads != nil {
+		opts = middleware.AddPayloadLogging(options.Payloads, opts)
+	}
+	opts = middleware.AddRecovery(opts)
+	opts = middleware.AddMetrics(opts)
+	if options.Audit != nil {
+		opts = middleware.AddAudit(options.Audit, opts)
+	}
+	if options.RateLimiter != nil {
+		opts = middleware.AddRateLimit(options.RateLimiter, opts)
+	}
+	if options.Timeouts != nil {
+		opts = middleware.AddTimeouts(options.Timeouts, opts)
+	}
+	opts = middleware.AddValidation(opts)
 
 	server := grpc.NewServer(opts...)
 	v1.RegisterFooServiceServer(server, v1API)
+	if options.AuditAPI != nil {
+		v1.RegisterAuditServiceServer(server, options.AuditAPI)
+	}
 
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("v1.FooService", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	middleware.InitializeMetrics(server)
+
+	return &Server{
+		options:      options,
+		server:       server,
+		healthServer: healthServer,
+		listener:     listen,
+		inProcess:    bufconn.Listen(inProcessBufferSize),
+	}, nil
+}
+
+func serve(server *grpc.Server, l net.Listener) error {
+	if err := server.Serve(l); err != grpc.ErrServerStopped {
+		return err
+	}
+	return nil
+}
+
+// Serve serves the in-process listener and, if any, the TCP listener until shut down.
+func (s *Server) Serve() error {
+	logger.Log.Info("Starting gRPC server...")
+	if s.listener == nil {
+		return serve(s.server, s.inProcess)
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- serve(s.server, s.inProcess) }()
+	if err := serve(s.server, s.listener); err != nil {
+		return err
+	}
+	return <-errCh
+}
+
+// DialInProcess connects to the server through an in-memory pipe, so in-process callers
+// such as the HTTP gateway go through the interceptor chain without a loopback dial.
+// The connection's message size limits mirror the server's.
+func (s *Server) DialInProcess(ctx context.Context, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	var callOpts []grpc.CallOption
+	if s.options.MaxSendMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallRecvMsgSize(s.options.MaxSendMsgSize))
+	}
+	if s.options.MaxRecvMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallSendMsgSize(s.options.MaxRecvMsgSize))
+	}
+	opts = append([]grpc.DialOption{
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return s.inProcess.DialContext(ctx)
+		}),
+		grpc.WithDefaultCallOptions(callOpts...),
+	}, opts...)
+	return grpc.DialContext(ctx, InProcessNetwork, opts...)
+}
+
+// ServeHTTP serves gRPC over an HTTP/2 connection accepted by an http.Server,
+// used when gRPC shares a port with the HTTP gateway.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.server.ServeHTTP(w, r)
+}
+
+// GRPCWeb wraps the server for browser gRPC-Web clients. Cross-origin calls are served for
+// origins accepted by allowOrigin; the wrapper exposes grpc-status and grpc-message to
+// scripts itself. Streaming calls work over fetch/XHR and over the websocket transport.
+func (s *Server) GRPCWeb(allowOrigin func(origin string) bool) *grpcweb.WrappedGrpcServer {
+	return grpcweb.WrapServer(s.server,
+		grpcweb.WithOriginFunc(allowOrigin),
+		grpcweb.WithWebsockets(true),
+		grpcweb.WithWebsocketOriginFunc(func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			if len(origin) == 0 {
+				return true
+			}
+			if u, err := url.Parse(origin); err == nil && u.Host == r.Host {
+				return true
+			}
+			return allowOrigin(origin)
+		}),
+	)
+}
+
+// Shutdown reports NOT_SERVING to health checks, then waits for in-flight RPCs
+// to finish. Remaining connections are closed forcibly once ctx expires.
+func (s *Server) Shutdown(ctx context.Context) error {
+	logger.Log.Warn("Shutting down gRPC server...")
+	s.healthServer.Shutdown()
+
+	done := make(chan struct{})
 	go func() {
-		for range c {
-			logger.Log.Warn("Shutting down gRPC server...")
-			server.GracefulStop()
-			<-ctx.Done()
-		}
+		s.server.GracefulStop()
+		close(done)
 	}()
 
-	logger.Log.Info("Starting gRPC server...")
-	return server.Serve(listen)
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
 }
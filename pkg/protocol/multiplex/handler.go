@@ -0,0 +1,35 @@
+// Package multiplex routes native gRPC, gRPC-Web and HTTP/REST gateway requests arriving on one listener.
+package multiplex
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func isGRPC(r *http.Request) bool {
+	return r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") &&
+		!strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc-web")
+}
+
+// NewHandler routes requests by protocol and content type: HTTP/2 application/grpc to
+// grpcHandler, gRPC-Web (including CORS preflight) to grpcWeb and everything else to rest.
+// HTTP/2 without TLS (h2c) is accepted so native gRPC clients can connect in plaintext.
+// grpcHandler is nil when native gRPC has a listener of its own. maxConcurrentStreams
+// limits streams per HTTP/2 connection, 0 for the default.
+func NewHandler(grpcHandler http.Handler, grpcWeb *grpcweb.WrappedGrpcServer, rest http.Handler, maxConcurrentStreams uint32) http.Handler {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case grpcHandler != nil && isGRPC(r):
+			grpcHandler.ServeHTTP(w, r)
+		case grpcWeb != nil && (grpcWeb.IsGrpcWebRequest(r) || grpcWeb.IsAcceptableGrpcCorsRequest(r)):
+			grpcWeb.ServeHTTP(w, r)
+		default:
+			rest.ServeHTTP(w, r)
+		}
+	})
+	return h2c.NewHandler(h, &http2.Server{MaxConcurrentStreams: maxConcurrentStreams})
+}
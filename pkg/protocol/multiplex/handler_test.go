@@ -0,0 +1,245 @@
+package multiplex
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/proto"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+	grpcserver "github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/grpc"
+)
+
+const allowedOrigin = "https://app.example.com"
+
+type fooServer struct {
+	v1.UnimplementedFooServiceServer
+}
+
+func (*fooServer) Read(ctx context.Context, req *v1.ReadRequest) (*v1.ReadResponse, error) {
+	return &v1.ReadResponse{ApiVersion: "v1", Foo: &v1.Foo{Id: req.Id}}, nil
+}
+
+// newTestServer serves native gRPC, gRPC-Web and a REST handler answering "rest" on one port.
+func newTestServer(t *testing.T) *httptest.Server {
+	s, err := grpcserver.NewServer(&fooServer{}, "", grpcserver.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rest := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "rest")
+	})
+	grpcWeb := s.GRPCWeb(func(origin string) bool { return origin == allowedOrigin })
+	ts := httptest.NewServer(NewHandler(s, grpcWeb, rest, 0))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// grpcWebFrame encodes msg as a gRPC-Web data frame.
+func grpcWebFrame(t *testing.T, msg proto.Message) []byte {
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame := make([]byte, 5, 5+len(b))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(b)))
+	return append(frame, b...)
+}
+
+// readFrame reads one gRPC-Web frame, returning whether it is the trailer frame.
+func readFrame(r io.Reader) (payload []byte, trailer bool, err error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, false, err
+	}
+	payload = make([]byte, binary.BigEndian.Uint32(header[1:]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, false, err
+	}
+	return payload, header[0]&0x80 != 0, nil
+}
+
+func grpcWebRequest(ctx context.Context, t *testing.T, url, method string, msg proto.Message) *http.Request {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, url+method, bytes.NewReader(grpcWebFrame(t, msg)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/grpc-web+proto")
+	r.Header.Set("X-Grpc-Web", "1")
+	r.Header.Set("Origin", allowedOrigin)
+	return r
+}
+
+func TestGRPCWeb_unary(t *testing.T) {
+	ts := newTestServer(t)
+
+	tests := []struct {
+		name       string
+		id         int64
+		wantStatus string
+	}{
+		{name: "01 - OK", id: 42, wantStatus: "0"},
+		{name: "02 - Invalid argument", id: 0, wantStatus: "3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := grpcWebRequest(context.Background(), t, ts.URL, "/v1.FooService/Read", &v1.ReadRequest{ApiVersion: "v1", Id: tt.id})
+			resp, err := ts.Client().Do(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if got := resp.Header.Get("Access-Control-Allow-Origin"); got != allowedOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q", got)
+			}
+			exposed := strings.ToLower(resp.Header.Get("Access-Control-Expose-Headers"))
+			if !strings.Contains(exposed, "grpc-status") || !strings.Contains(exposed, "grpc-message") {
+				t.Errorf("Access-Control-Expose-Headers = %q", exposed)
+			}
+
+			// Errors without a response are sent in the headers, otherwise grpc-status is in the trailer frame.
+			gotStatus := resp.Header.Get("Grpc-Status")
+			var got v1.ReadResponse
+			for {
+				payload, trailer, err := readFrame(resp.Body)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !trailer {
+					if err := proto.Unmarshal(payload, &got); err != nil {
+						t.Fatal(err)
+					}
+					continue
+				}
+				h, err := textproto.NewReader(bufio.NewReader(bytes.NewReader(append(payload, '\r', '\n')))).ReadMIMEHeader()
+				if err != nil {
+					t.Fatalf("invalid trailer %q: %v", payload, err)
+				}
+				gotStatus = h.Get("Grpc-Status")
+			}
+			if gotStatus != tt.wantStatus {
+				t.Errorf("grpc-status = %q, want %q", gotStatus, tt.wantStatus)
+			}
+			if tt.wantStatus == "0" && got.Foo.GetId() != tt.id {
+				t.Errorf("response = %v", &got)
+			}
+		})
+	}
+}
+
+func TestGRPCWeb_serverStreaming(t *testing.T) {
+	ts := newTestServer(t)
+
+	// Watch keeps the stream open after the current status, so the call is cancelled once it arrives.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := grpcWebRequest(ctx, t, ts.URL, "/grpc.health.v1.Health/Watch", &healthpb.HealthCheckRequest{Service: "v1.FooService"})
+	resp, err := ts.Client().Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/grpc-web") {
+		t.Fatalf("status code = %d, content type = %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	payload, trailer, err := readFrame(resp.Body)
+	if err != nil || trailer {
+		t.Fatalf("expected a message frame, got trailer = %v, error = %v", trailer, err)
+	}
+	var got healthpb.HealthCheckResponse
+	if err := proto.Unmarshal(payload, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("status = %v, want SERVING", got.Status)
+	}
+}
+
+func TestGRPCWeb_disallowedOrigin(t *testing.T) {
+	ts := newTestServer(t)
+
+	preflight, err := http.NewRequest(http.MethodOptions, ts.URL+"/v1.FooService/Read", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	preflight.Header.Set("Origin", "https://evil.example.com")
+	preflight.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	preflight.Header.Set("Access-Control-Request-Headers", "content-type,x-grpc-web")
+	resp, err := ts.Client().Do(preflight)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); len(got) > 0 {
+		t.Errorf("preflight allowed origin %q", got)
+	}
+
+	r := grpcWebRequest(context.Background(), t, ts.URL, "/v1.FooService/Read", &v1.ReadRequest{ApiVersion: "v1", Id: 42})
+	r.Header.Set("Origin", "https://evil.example.com")
+	resp, err = ts.Client().Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); len(got) > 0 {
+		t.Errorf("response allowed origin %q", got)
+	}
+}
+
+func TestNewHandler_routing(t *testing.T) {
+	ts := newTestServer(t)
+
+	// Native gRPC over plaintext HTTP/2.
+	conn, err := grpc.Dial(strings.TrimPrefix(ts.URL, "http://"), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	res, err := v1.NewFooServiceClient(conn).Read(context.Background(), &v1.ReadRequest{ApiVersion: "v1", Id: 7})
+	if err != nil || res.Foo.GetId() != 7 {
+		t.Errorf("h2c Read() = %v, %v", res, err)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		wantBody    string
+	}{
+		{name: "01 - REST", contentType: "application/json", wantBody: "rest"},
+		// Native gRPC requires HTTP/2, so HTTP/1.1 application/grpc falls through to the gateway.
+		{name: "02 - HTTP/1.1 gRPC", contentType: "application/grpc", wantBody: "rest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := http.NewRequest(http.MethodPost, ts.URL+"/v1.FooService/Read", strings.NewReader("{}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.Header.Set("Content-Type", tt.contentType)
+			resp, err := ts.Client().Do(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+// Package payload renders request and response payloads for debug logging with
+// sensitive fields redacted and large payloads truncated.
+package payload
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+)
+
+// Redacted replaces the value of sensitive fields.
+const Redacted = "<redacted>"
+
+// Options configures payload logging.
+type Options struct {
+	Enabled bool
+	// RedactPaths are field paths to redact in addition to the fields marked (sensitive) in the
+	// protos, e.g. "foo.title". A path matches wherever it ends a field path; "title" matches
+	// every title field. Proto names and JSON names are interchangeable.
+	RedactPaths []string
+	// MaxBytes truncates the rendered payload; zero or less disables truncation.
+	MaxBytes int
+	// SampleRate is the fraction of calls whose payloads are logged, between 0 and 1.
+	SampleRate float64
+}
+
+// Policy decides which calls have their payloads logged and renders them.
+type Policy struct {
+	Options
+	paths [][]string
+}
+
+func NewPolicy(o Options) *Policy {
+	p := &Policy{Options: o}
+	for _, path := range o.RedactPaths {
+		var segments []string
+		for _, s := range strings.Split(path, ".") {
+			if s = normalize(s); len(s) > 0 {
+				segments = append(segments, s)
+			}
+		}
+		if len(segments) > 0 {
+			p.paths = append(p.paths, segments)
+		}
+	}
+	return p
+}
+
+// Sampled reports whether the payloads of a call should be logged.
+func (p *Policy) Sampled() bool {
+	return p.Enabled && (p.SampleRate >= 1 || rand.Float64() < p.SampleRate)
+}
+
+// Message renders m as JSON with proto field names.
+func (p *Policy) Message(m proto.Message) string {
+	b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+	if err != nil {
+		return fmt.Sprintf("<unloggable payload: %v>", err)
+	}
+	return p.JSON(b, m.ProtoReflect().Descriptor())
+}
+
+// JSON renders a JSON document, or a newline-delimited stream of them, holding messages
+// of type md. Stream items may be wrapped in a "result" field, as the gateway streams them.
+// Fields marked (sensitive) are only known when md is set. Anything but JSON is summarised
+// by its size, since it cannot be redacted.
+func (p *Policy) JSON(b []byte, md protoreflect.MessageDescriptor) string {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var out []string
+	for {
+		var v interface{}
+		err := dec.Decode(&v)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Sprintf("<%d bytes of non-JSON payload>", len(b))
+		}
+		if obj, ok := v.(map[string]interface{}); ok && obj["result"] != nil && field(md, "result") == nil {
+			obj["result"] = p.redact(obj["result"], nil, md)
+		} else {
+			v = p.redact(v, nil, md)
+		}
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			return fmt.Sprintf("<unloggable payload: %v>", err)
+		}
+		out = append(out, strings.TrimSuffix(buf.String(), "\n"))
+	}
+	return p.truncate(strings.Join(out, "\n"))
+}
+
+func (p *Policy) truncate(s string) string {
+	if p.MaxBytes <= 0 || len(s) <= p.MaxBytes {
+		return s
+	}
+	cut := p.MaxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s...<truncated, %d bytes>", s[:cut], len(s))
+}
+
+// redact redacts v, a value of a message of type md if md is set, found at path.
+func (p *Policy) redact(v interface{}, path []string, md protoreflect.MessageDescriptor) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			childPath := append(path[:len(path):len(path)], normalize(k))
+			fd := field(md, k)
+			if p.sensitive(childPath, fd) {
+				t[k] = Redacted
+				continue
+			}
+			if fd != nil && fd.IsMap() {
+				if entries, ok := child.(map[string]interface{}); ok {
+					for key, value := range entries {
+						entries[key] = p.redact(value, childPath, fd.MapValue().Message())
+					}
+				}
+				continue
+			}
+			var childMD protoreflect.MessageDescriptor
+			if fd != nil {
+				childMD = fd.Message()
+			}
+			t[k] = p.redact(child, childPath, childMD)
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = p.redact(t[i], path, md)
+		}
+	}
+	return v
+}
+
+// field returns the field of md with the proto or JSON name name, or nil.
+func field(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	if md == nil {
+		return nil
+	}
+	if fd := md.Fields().ByName(protoreflect.Name(name)); fd != nil {
+		return fd
+	}
+	return md.Fields().ByJSONName(name)
+}
+
+func (p *Policy) sensitive(path []string, fd protoreflect.FieldDescriptor) bool {
+	if fd != nil && sensitiveFields()[fd.FullName()] {
+		return true
+	}
+	for _, suffix := range p.paths {
+		if len(suffix) > len(path) {
+			continue
+		}
+		match := true
+		for i, s := range suffix {
+			if path[len(path)-len(suffix)+i] != s {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize lets proto names and JSON names match, e.g. created_by and createdBy.
+func normalize(name string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", ""))
+}
+
+var (
+	sensitiveOnce sync.Once
+	sensitiveSet  map[protoreflect.FullName]bool
+)
+
+// sensitiveFields returns the full names, e.g. v1.Foo.desc, of the fields marked (sensitive)
+// in any registered proto.
+func sensitiveFields() map[protoreflect.FullName]bool {
+	sensitiveOnce.Do(func() {
+		sensitiveSet = map[protoreflect.FullName]bool{}
+		protoregistry.GlobalFiles.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+			collectSensitive(fd.Messages())
+			return true
+		})
+	})
+	return sensitiveSet
+}
+
+func collectSensitive(msgs protoreflect.MessageDescriptors) {
+	for i := 0; i < msgs.Len(); i++ {
+		md := msgs.Get(i)
+		fields := md.Fields()
+		for j := 0; j < fields.Len(); j++ {
+			fd := fields.Get(j)
+			if s, ok := proto.GetExtension(fd.Options(), v1.E_Sensitive).(bool); ok && s {
+				sensitiveSet[fd.FullName()] = true
+			}
+		}
+		collectSensitive(md.Messages())
+	}
+}
+
+// ReloadablePolicy holds a Policy that can be replaced while the server is running.
+type ReloadablePolicy struct {
+	v atomic.Value
+}
+
+func NewReloadablePolicy(o Options) *ReloadablePolicy {
+	r := &ReloadablePolicy{}
+	r.Store(o)
+	return r
+}
+
+// Store replaces the policy applied to calls from now on.
+func (r *ReloadablePolicy) Store(o Options) {
+	r.v.Store(NewPolicy(o))
+}
+
+// Load returns the policy in effect.
+func (r *ReloadablePolicy) Load() *Policy {
+	return r.v.Load().(*Policy)
+}
@@ -0,0 +1,90 @@
+package payload
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+)
+
+func TestPolicy_Message(t *testing.T) {
+	req := &v1.CreateRequest{
+		ApiVersion: "v1",
+		Foo: &v1.Foo{
+			Title:     "title",
+			Desc:      "Jane's phone number",
+			SysFields: &v1.SystemFields{CreatedBy: "jane@example.com"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		options Options
+		want    string
+	}{
+		{
+			name: "01 - Sensitive fields",
+			want: `{"api_version":"v1","foo":{"desc":"<redacted>","sys_fields":{"created_by":"<redacted>"},"title":"title"}}`,
+		},
+		{
+			name:    "02 - Configured paths",
+			options: Options{RedactPaths: []string{"foo.title", "apiVersion"}},
+			want:    `{"api_version":"<redacted>","foo":{"desc":"<redacted>","sys_fields":{"created_by":"<redacted>"},"title":"<redacted>"}}`,
+		},
+		{
+			name:    "03 - Truncated",
+			options: Options{MaxBytes: 20},
+			want:    `{"api_version":"v1",...<truncated, 105 bytes>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewPolicy(tt.options).Message(req); got != tt.want {
+				t.Errorf("Message() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicy_JSON(t *testing.T) {
+	p := NewPolicy(Options{})
+
+	tests := []struct {
+		name string
+		body string
+		md   protoreflect.MessageDescriptor
+		want string
+	}{
+		{
+			name: "01 - JSON names",
+			body: `{"foo":{"id":"1","sysFields":{"updatedBy":"jane"}}}`,
+			md:   (&v1.UpdateRequest{}).ProtoReflect().Descriptor(),
+			want: `{"foo":{"id":"1","sysFields":{"updatedBy":"<redacted>"}}}`,
+		},
+		{
+			name: "02 - Stream",
+			body: "{\"result\":{\"desc\":\"a\"}}\n{\"result\":{\"desc\":\"b\"}}\n",
+			md:   (&v1.Foo{}).ProtoReflect().Descriptor(),
+			want: "{\"result\":{\"desc\":\"<redacted>\"}}\n{\"result\":{\"desc\":\"<redacted>\"}}",
+		},
+		{
+			name: "03 - Not JSON",
+			body: "desc=secret",
+			want: "<11 bytes of non-JSON payload>",
+		},
+		{
+			name: "04 - Sensitive name outside its message",
+			body: `{"api_version":"v1","desc":"not a Foo","foos":[{"desc":"a"}]}`,
+			md:   (&v1.ReadAllResponse{}).ProtoReflect().Descriptor(),
+			want: `{"api_version":"v1","desc":"not a Foo","foos":[{"desc":"<redacted>"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.JSON([]byte(tt.body), tt.md); got != tt.want {
+				t.Errorf("JSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
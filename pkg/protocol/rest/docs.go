@@ -0,0 +1,80 @@
+package rest
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"html/template"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+
+	"github.com/wingkwong/go-grpc-boilerplate/api/swagger"
+)
+
+// docsScriptPath serves the embedded Redoc bundle, loaded by /docs unless another script URL is configured.
+const docsScriptPath = "/docs/redoc.standalone.js"
+
+// redocSHA256 pins docs/redoc.standalone.js: Redoc 2.0.0-rc.59, as vendored in the Go module
+// github.com/mvrilo/go-redoc v0.1.4 under assets/. Update both together.
+const redocSHA256 = "cf38f3090cc2dad2f11a6d7b9cea68fe41eb00d2c969fb8d4d1df83110ce3ac7"
+
+//go:embed docs/index.html
+var docsFS embed.FS
+
+//go:embed docs/redoc.standalone.js
+var redocScript []byte
+
+var docsTemplate = template.Must(template.ParseFS(docsFS, "docs/index.html"))
+
+// openAPIV3 converts the embedded OpenAPI v2 document to OpenAPI v3.
+func openAPIV3(v2 []byte) ([]byte, error) {
+	var doc openapi2.T
+	if err := json.Unmarshal(v2, &doc); err != nil {
+		return nil, err
+	}
+	doc3, err := openapi2conv.ToV3(&doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc3)
+}
+
+func jsonDocHandler(doc []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(doc)
+	})
+}
+
+// registerDocs serves the OpenAPI v2 document at /openapi.json, its OpenAPI v3
+// conversion at /openapi.v3.json and a Redoc UI for the v2 document at /docs.
+// The UI loads the embedded Redoc bundle unless scriptURL is set.
+func registerDocs(mux *http.ServeMux, scriptURL string) error {
+	v3, err := openAPIV3(swagger.FooServiceV1)
+	if err != nil {
+		return err
+	}
+
+	if len(scriptURL) == 0 {
+		scriptURL = docsScriptPath
+		mux.HandleFunc(docsScriptPath, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+			_, _ = w.Write(redocScript)
+		})
+	}
+
+	var page bytes.Buffer
+	if err := docsTemplate.Execute(&page, struct{ SpecURL, ScriptURL string }{"/openapi.json", scriptURL}); err != nil {
+		return err
+	}
+
+	mux.Handle("/openapi.json", jsonDocHandler(swagger.FooServiceV1))
+	mux.Handle("/openapi.v3.json", jsonDocHandler(v3))
+	mux.HandleFunc("/docs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(page.Bytes())
+	})
+	return nil
+}
@@ -0,0 +1,73 @@
+package rest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_registerDocs(t *testing.T) {
+	mux := http.NewServeMux()
+	if err := registerDocs(mux, "https://example.test/redoc.js"); err != nil {
+		t.Fatalf("registerDocs() error = %v", err)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s status code = %d", path, w.Code)
+		}
+		return w
+	}
+
+	var v2 struct {
+		Swagger string                 `json:"swagger"`
+		Paths   map[string]interface{} `json:"paths"`
+	}
+	if err := json.Unmarshal(get("/openapi.json").Body.Bytes(), &v2); err != nil || v2.Swagger != "2.0" {
+		t.Errorf("/openapi.json is not an OpenAPI v2 document: %v", err)
+	}
+
+	var v3 struct {
+		OpenAPI string                 `json:"openapi"`
+		Paths   map[string]interface{} `json:"paths"`
+	}
+	if err := json.Unmarshal(get("/openapi.v3.json").Body.Bytes(), &v3); err != nil || !strings.HasPrefix(v3.OpenAPI, "3.") {
+		t.Errorf("/openapi.v3.json is not an OpenAPI v3 document: %v", err)
+	}
+	if len(v3.Paths) != len(v2.Paths) {
+		t.Errorf("OpenAPI v3 has %d paths, want %d", len(v3.Paths), len(v2.Paths))
+	}
+
+	page := get("/docs").Body.String()
+	if !strings.Contains(page, `spec-url="/openapi.json"`) || !strings.Contains(page, "https://example.test/redoc.js") {
+		t.Errorf("unexpected /docs page %s", page)
+	}
+}
+
+func Test_registerDocs_embeddedScript(t *testing.T) {
+	mux := http.NewServeMux()
+	if err := registerDocs(mux, ""); err != nil {
+		t.Fatalf("registerDocs() error = %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if page := w.Body.String(); !strings.Contains(page, `src="`+docsScriptPath+`"`) || strings.Contains(page, "https://") {
+		t.Errorf("/docs should only load the embedded bundle: %s", page)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, docsScriptPath, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s status code = %d, want %d", docsScriptPath, w.Code, http.StatusOK)
+	}
+	if sum := sha256.Sum256(w.Body.Bytes()); hex.EncodeToString(sum[:]) != redocSHA256 {
+		t.Errorf("GET %s does not serve the pinned Redoc bundle", docsScriptPath)
+	}
+}
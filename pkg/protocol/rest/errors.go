@@ -0,0 +1,106 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/logger"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/requestid"
+)
+
+// errorEnvelope is the JSON body of every gateway error response:
+//
+//	{"error": {"code": 404, "status": "NOT_FOUND", "message": "...", "reason": "NOT_FOUND",
+//	           "request_id": "...", "details": [{"@type": "type.googleapis.com/google.rpc.ResourceInfo", ...}]}}
+type errorEnvelope struct {
+	Error errorStatus `json:"error"`
+}
+
+type errorStatus struct {
+	Code      int               `json:"code"`
+	Status    string            `json:"status"`
+	Message   string            `json:"message"`
+	Reason    string            `json:"reason,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+	Details   []json.RawMessage `json:"details,omitempty"`
+}
+
+var detailMarshaler = protojson.MarshalOptions{UseProtoNames: true}
+
+func newErrorEnvelope(ctx context.Context, s *status.Status) errorEnvelope {
+	body := errorStatus{
+		Code:      runtime.HTTPStatusFromCode(s.Code()),
+		Status:    code.Code_name[int32(s.Code())],
+		Message:   s.Message(),
+		RequestID: requestid.FromContext(ctx),
+	}
+	for _, d := range s.Details() {
+		if ei, ok := d.(*errdetails.ErrorInfo); ok {
+			body.Reason = ei.Reason
+		}
+	}
+	for _, a := range s.Proto().GetDetails() {
+		b, err := detailMarshaler.Marshal(a)
+		if err != nil {
+			logger.Log.Warn("Failed to marshal error detail", zap.String("type", a.GetTypeUrl()), zap.Error(err))
+			continue
+		}
+		body.Details = append(body.Details, b)
+	}
+	return errorEnvelope{Error: body}
+}
+
+// retryAfter returns the RetryInfo delay in whole seconds, if any.
+func retryAfter(s *status.Status) (string, bool) {
+	for _, d := range s.Details() {
+		if ri, ok := d.(*errdetails.RetryInfo); ok && ri.RetryDelay != nil {
+			secs := int64(math.Ceil(ri.RetryDelay.AsDuration().Seconds()))
+			if secs < 1 {
+				secs = 1
+			}
+			return strconv.FormatInt(secs, 10), true
+		}
+	}
+	return "", false
+}
+
+// errorHandler renders gRPC errors as an errorEnvelope, forwards response header
+// metadata and sets Retry-After when the status carries RetryInfo (e.g. 429 Too Many Requests).
+func errorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	s, ok := status.FromError(err)
+	if !ok {
+		s = status.New(codes.Unknown, err.Error())
+	}
+
+	if md, ok := runtime.ServerMetadataFromContext(ctx); ok {
+		for k, vs := range md.HeaderMD {
+			if h, ok := outgoingHeaderMatcher(k); ok {
+				for _, v := range vs {
+					w.Header().Add(h, v)
+				}
+			}
+		}
+	}
+
+	if v, ok := retryAfter(s); ok {
+		w.Header().Set("Retry-After", v)
+	}
+
+	w.Header().Del("Trailer")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(runtime.HTTPStatusFromCode(s.Code()))
+	if err := json.NewEncoder(w).Encode(newErrorEnvelope(r.Context(), s)); err != nil {
+		logger.Log.Warn("Failed to write error response", zap.Error(err))
+	}
+}
@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/apierrors"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/requestid"
+)
+
+func Test_errorHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   int
+		wantStatus string
+		wantReason string
+		wantRetry  string
+	}{
+		{
+			name:       "01 - NotFound",
+			err:        apierrors.NotFound("Foo", "42"),
+			wantCode:   http.StatusNotFound,
+			wantStatus: "NOT_FOUND",
+			wantReason: apierrors.ReasonNotFound,
+		},
+		{
+			name:       "02 - ResourceExhausted",
+			err:        apierrors.ResourceExhausted(apierrors.ReasonRateLimited, "slow down", 1500*time.Millisecond),
+			wantCode:   http.StatusTooManyRequests,
+			wantStatus: "RESOURCE_EXHAUSTED",
+			wantReason: apierrors.ReasonRateLimited,
+			wantRetry:  "2",
+		},
+		{
+			name:       "03 - Internal hides cause",
+			err:        apierrors.Internal(context.Background(), apierrors.ReasonInternal, "Failed to insert into record", context.DeadlineExceeded),
+			wantCode:   http.StatusInternalServerError,
+			wantStatus: "INTERNAL",
+			wantReason: apierrors.ReasonInternal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/foo/42", nil)
+			r = r.WithContext(requestid.NewContext(r.Context(), "req-1"))
+
+			errorHandler(context.Background(), runtime.NewServeMux(), &runtime.JSONPb{}, w, r, tt.err)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status code = %d, want %d", w.Code, tt.wantCode)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetry {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetry)
+			}
+
+			var body struct {
+				Error struct {
+					Code      int               `json:"code"`
+					Status    string            `json:"status"`
+					Message   string            `json:"message"`
+					Reason    string            `json:"reason"`
+					RequestID string            `json:"request_id"`
+					Details   []json.RawMessage `json:"details"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid JSON body %q: %v", w.Body.String(), err)
+			}
+			if body.Error.Code != tt.wantCode || body.Error.Status != tt.wantStatus || body.Error.Reason != tt.wantReason {
+				t.Errorf("unexpected envelope %+v", body.Error)
+			}
+			if body.Error.RequestID != "req-1" {
+				t.Errorf("request_id = %q", body.Error.RequestID)
+			}
+			if strings.Contains(w.Body.String(), context.DeadlineExceeded.Error()) {
+				t.Errorf("internal cause leaked to client: %s", w.Body.String())
+			}
+			if len(body.Error.Details) < 2 {
+				t.Errorf("expected ErrorInfo and a specific detail, got %s", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package rest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/grpc/middleware"
+	restmiddleware "github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/rest/middleware"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/requestid"
+)
+
+// requestIDAnnotator forwards the request ID assigned by the middleware to gRPC.
+func requestIDAnnotator(ctx context.Context, r *http.Request) metadata.MD {
+	if id := requestid.FromContext(r.Context()); id != "" {
+		return metadata.Pairs(requestid.MetadataKey, id)
+	}
+	return nil
+}
+
+// userAnnotator forwards the user authenticated by the gateway to gRPC.
+func userAnnotator(ctx context.Context, r *http.Request) metadata.MD {
+	if user := restmiddleware.User(r); user != "" {
+		return metadata.Pairs(middleware.UserMetadataKey, user)
+	}
+	return nil
+}
+
+// peerAnnotator forwards the addresses of the gateway's peer, for auditing, and of its
+// client behind proxies, for rate limiting, to gRPC. The in-process gRPC peer would otherwise
+// hide them.
+func peerAnnotator(proxies middleware.Proxies) func(context.Context, *http.Request) metadata.MD {
+	return func(ctx context.Context, r *http.Request) metadata.MD {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		md := metadata.MD{}
+		if host != "" {
+			md.Set(middleware.PeerMetadataKey, host)
+		}
+		if addr := proxies.Client(host, r.Header.Values("X-Forwarded-For")); addr != "" {
+			md.Set(middleware.ClientMetadataKey, addr)
+		}
+		return md
+	}
+}
+
+// outgoingHeaderMatcher maps gRPC response metadata to Grpc-Metadata-* headers, except the
+// request ID, which the middleware already returns as X-Request-Id.
+func outgoingHeaderMatcher(key string) (string, bool) {
+	if key == requestid.MetadataKey {
+		return "", false
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
+
+// headerMatcher forwards the caller's API key and tenant to gRPC in addition to the default headers.
+func headerMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, middleware.APIKeyHeader) {
+		return middleware.APIKeyHeader, true
+	}
+	if strings.EqualFold(key, middleware.TenantHeader) {
+		return middleware.TenantHeader, true
+	}
+	// The request ID is forwarded by requestIDAnnotator once validated.
+	// The authenticated user, peer and client are set by userAnnotator and peerAnnotator
+	// only; client-supplied ones are dropped.
+	if strings.EqualFold(key, runtime.MetadataHeaderPrefix+requestid.Header) ||
+		strings.EqualFold(key, runtime.MetadataHeaderPrefix+middleware.UserMetadataKey) ||
+		strings.EqualFold(key, runtime.MetadataHeaderPrefix+middleware.PeerMetadataKey) ||
+		strings.EqualFold(key, runtime.MetadataHeaderPrefix+middleware.ClientMetadataKey) {
+		return "", false
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/grpc/middleware"
+)
+
+type fooServer struct {
+	v1.UnimplementedFooServiceServer
+}
+
+func (*fooServer) Read(ctx context.Context, req *v1.ReadRequest) (*v1.ReadResponse, error) {
+	return &v1.ReadResponse{ApiVersion: "v1", Foo: &v1.Foo{Id: req.Id}}, nil
+}
+
+// newTestGateway serves the gateway in front of an in-process gRPC server built with opts.
+func newTestGateway(t *testing.T, options Options, opts ...grpc.ServerOption) http.Handler {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(opts...)
+	v1.RegisterFooServiceServer(s, &fooServer{})
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial(middleware.InProcessNetwork, grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	h, err := NewHandler(context.Background(), conn, func(context.Context) error { return nil }, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestGateway_rateLimitsByPeer(t *testing.T) {
+	limiter := middleware.NewRateLimiter(middleware.RateLimit{RPS: 0.001, Burst: 1}, nil)
+	proxies, err := middleware.ParseProxies("127.0.0.1,10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newTestGateway(t, Options{TrustedProxies: proxies}, middleware.AddRateLimit(limiter, nil)...)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		header     http.Header
+		wantCode   int
+	}{
+		{name: "01 - First call", remoteAddr: "192.0.2.1:1000", wantCode: http.StatusOK},
+		{name: "02 - Spoofed X-Forwarded-For", remoteAddr: "192.0.2.1:1001", header: http.Header{"X-Forwarded-For": {"203.0.113.1"}}, wantCode: http.StatusTooManyRequests},
+		{name: "03 - Spoofed peer metadata", remoteAddr: "192.0.2.1:1002", header: http.Header{"Grpc-Metadata-X-Gateway-Peer": {"203.0.113.2"}}, wantCode: http.StatusTooManyRequests},
+		{name: "04 - Other client", remoteAddr: "192.0.2.2:1000", wantCode: http.StatusOK},
+		{name: "05 - Local proxy", remoteAddr: "127.0.0.1:1000", header: http.Header{"X-Forwarded-For": {"203.0.113.3, 192.0.2.1"}}, wantCode: http.StatusTooManyRequests},
+		{name: "06 - Load balancer", remoteAddr: "10.0.1.5:1000", header: http.Header{"X-Forwarded-For": {"203.0.113.4"}}, wantCode: http.StatusOK},
+		{name: "07 - Other load balancer client", remoteAddr: "10.0.1.5:1001", header: http.Header{"X-Forwarded-For": {"203.0.113.5"}}, wantCode: http.StatusOK},
+		{name: "08 - Same client via another load balancer node", remoteAddr: "10.0.2.6:1000", header: http.Header{"X-Forwarded-For": {"203.0.113.4"}}, wantCode: http.StatusTooManyRequests},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/foo/"+strconv.Itoa(i+1), nil)
+			r.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.header {
+				r.Header[k] = v
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.wantCode {
+				t.Errorf("status code = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+		})
+	}
+}
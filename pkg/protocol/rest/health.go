@@ -0,0 +1,88 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/logger"
+)
+
+const readinessTimeout = 2 * time.Second
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+func writeHealth(w http.ResponseWriter, code int, resp healthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// healthzHandler reports liveness: the process is up and serving HTTP.
+func healthzHandler(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, http.StatusOK, healthResponse{Status: "ok"})
+}
+
+// readyzHandler reports readiness: shutdown has not started, the database answers a ping
+// and the gateway's connection to the gRPC server is ready, connecting it first if idle.
+func readyzHandler(dbPing func(context.Context) error, conn *grpc.ClientConn, draining func() bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if draining != nil && draining() {
+			writeHealth(w, http.StatusServiceUnavailable, healthResponse{Status: "draining"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+
+		resp := healthResponse{Status: "ok", Checks: map[string]string{}}
+		code := http.StatusOK
+
+		if err := dbPing(ctx); err != nil {
+			// The probe is unauthenticated, so the cause only goes to the log.
+			logger.Log.Warn("Readiness check failed", zap.String("check", "database"), zap.Error(err))
+			resp.Checks["database"] = "unavailable"
+			code = http.StatusServiceUnavailable
+		} else {
+			resp.Checks["database"] = "ok"
+		}
+
+		if state := awaitReady(ctx, conn); state == connectivity.Ready {
+			resp.Checks["grpc"] = "ok"
+		} else {
+			resp.Checks["grpc"] = state.String()
+			code = http.StatusServiceUnavailable
+		}
+
+		if code != http.StatusOK {
+			resp.Status = "unavailable"
+		}
+		writeHealth(w, code, resp)
+	}
+}
+
+// awaitReady connects conn if it is idle and waits until it is ready or ctx is done,
+// returning the last state seen.
+func awaitReady(ctx context.Context, conn *grpc.ClientConn) connectivity.State {
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready, connectivity.Shutdown:
+			return state
+		case connectivity.Idle:
+			conn.Connect()
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return conn.GetState()
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func Test_readyzHandler(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	go s.Serve(lis)
+	defer s.Stop()
+
+	conn, err := grpc.Dial("bufconn", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	tests := []struct {
+		name     string
+		dbErr    error
+		draining bool
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "01 - Ready",
+			wantCode: http.StatusOK,
+			wantBody: `{"status":"ok","checks":{"database":"ok","grpc":"ok"}}`,
+		},
+		{
+			name:     "02 - Database down hides cause",
+			dbErr:    errors.New("dial tcp 10.0.0.5:3306: connection refused"),
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: `{"status":"unavailable","checks":{"database":"unavailable","grpc":"ok"}}`,
+		},
+		{
+			name:     "03 - Shutting down",
+			draining: true,
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: `{"status":"draining"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := readyzHandler(func(context.Context) error { return tt.dbErr }, conn, func() bool { return tt.draining })
+			w := httptest.NewRecorder()
+			h(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if w.Code != tt.wantCode {
+				t.Errorf("status code = %d, want %d", w.Code, tt.wantCode)
+			}
+			if got := strings.TrimSpace(w.Body.String()); got != tt.wantBody {
+				t.Errorf("body = %s, want %s", got, tt.wantBody)
+			}
+		})
+	}
+}
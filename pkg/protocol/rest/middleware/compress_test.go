@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func Test_negotiateEncoding(t *testing.T) {
+	tests := map[string]string{
+		"":                       "",
+		"identity":               "",
+		"gzip":                   "gzip",
+		"gzip, deflate, br":      "br",
+		"br;q=0.5, gzip":         "gzip",
+		"br;q=0, gzip;q=0":       "",
+		"*":                      "br",
+		"GZIP;q=0.8, *;q=0.1":    "gzip",
+		"deflate, gzip;q=1.0, *": "br",
+	}
+	for accept, want := range tests {
+		if got := negotiateEncoding(accept); got != want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", accept, got, want)
+		}
+	}
+}
+
+func TestAddCompression(t *testing.T) {
+	large := `{"foos":[` + strings.Repeat(`{"title":"foo"},`, 200) + `{}]}`
+	small := `{"foo":{}}`
+
+	tests := []struct {
+		name         string
+		accept       string
+		body         string
+		wantEncoding string
+	}{
+		{name: "01 - gzip", accept: "gzip", body: large, wantEncoding: "gzip"},
+		{name: "02 - br", accept: "gzip, br", body: large, wantEncoding: "br"},
+		{name: "03 - Not accepted", accept: "", body: large},
+		{name: "04 - Too small", accept: "gzip", body: small},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := AddCompression(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				_, _ = io.WriteString(w, tt.body)
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/foo/all", nil)
+			r.Header.Set("Accept-Encoding", tt.accept)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != http.StatusCreated {
+				t.Errorf("status code = %d, want %d", w.Code, http.StatusCreated)
+			}
+			if got := w.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Fatalf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+
+			var body io.Reader = w.Body
+			switch tt.wantEncoding {
+			case "gzip":
+				zr, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body = zr
+			case "br":
+				body = brotli.NewReader(w.Body)
+			}
+			got, err := ioutil.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.body {
+				t.Errorf("decoded body differs from the original")
+			}
+		})
+	}
+}
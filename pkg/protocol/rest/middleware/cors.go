@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/cors"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/requestid"
+)
+
+// CORS configures cross-origin access to the gateway. A nil Origins disables CORS.
+type CORS struct {
+	Origins *cors.Origins
+	Methods []string
+	// Headers are the request headers browsers may send; "*" allows any.
+	Headers          []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// exposedHeaders are the response headers scripts may read besides the CORS-safelisted ones.
+var exposedHeaders = []string{requestid.Header, "Retry-After"}
+
+type corsPolicy struct {
+	CORS
+	methods   string
+	headers   string
+	anyHeader bool
+}
+
+// ReloadableCORS holds a CORS configuration that can be replaced while the server is running.
+type ReloadableCORS struct {
+	v atomic.Value
+}
+
+func NewReloadableCORS(c CORS) *ReloadableCORS {
+	r := &ReloadableCORS{}
+	r.Store(c)
+	return r
+}
+
+// Store replaces the CORS configuration applied to requests from now on.
+func (r *ReloadableCORS) Store(c CORS) {
+	p := &corsPolicy{
+		CORS:    c,
+		methods: strings.Join(c.Methods, ", "),
+		headers: strings.Join(c.Headers, ", "),
+	}
+	for _, hdr := range c.Headers {
+		p.anyHeader = p.anyHeader || hdr == "*"
+	}
+	r.v.Store(p)
+}
+
+// AddCORS answers preflight OPTIONS requests for every route and adds CORS headers to
+// responses for allowed origins. Requests without an Origin header pass through untouched.
+func AddCORS(policy *ReloadableCORS, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := policy.v.Load().(*corsPolicy)
+		origin := r.Header.Get("Origin")
+		if len(origin) == 0 || c.Origins == nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+
+		preflight := r.Method == http.MethodOptions && len(r.Header.Get("Access-Control-Request-Method")) > 0
+		if !c.Origins.Allowed(origin) {
+			if preflight {
+				writeError(w, r, http.StatusForbidden, "PERMISSION_DENIED", "CORS_ORIGIN_NOT_ALLOWED",
+					"Origin not allowed: "+origin)
+				return
+			}
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if c.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(exposedHeaders, ", "))
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		w.Header().Set("Access-Control-Allow-Methods", c.methods)
+		if c.anyHeader {
+			if requested := r.Header.Get("Access-Control-Request-Headers"); len(requested) > 0 {
+				w.Header().Set("Access-Control-Allow-Headers", requested)
+			}
+		} else if len(c.headers) > 0 {
+			w.Header().Set("Access-Control-Allow-Headers", c.headers)
+		}
+		if c.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge/time.Second)))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/cors"
+)
+
+func TestAddCORS(t *testing.T) {
+	origins, err := cors.ParseOrigins("https://*.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := AddCORS(NewReloadableCORS(CORS{
+		Origins:          origins,
+		Methods:          []string{"GET", "POST"},
+		Headers:          []string{"Content-Type", "X-Api-Key"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name       string
+		method     string
+		origin     string
+		preflight  bool
+		wantCode   int
+		wantHeader map[string]string
+	}{
+		{
+			name:     "01 - No origin",
+			method:   http.MethodGet,
+			wantCode: http.StatusOK,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name:     "02 - Allowed origin",
+			method:   http.MethodGet,
+			origin:   "https://app.example.com",
+			wantCode: http.StatusOK,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Expose-Headers":    "X-Request-Id, Retry-After",
+			},
+		},
+		{
+			name:     "03 - Disallowed origin",
+			method:   http.MethodGet,
+			origin:   "https://evil.test",
+			wantCode: http.StatusOK,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name:      "04 - Preflight",
+			method:    http.MethodOptions,
+			origin:    "https://app.example.com",
+			preflight: true,
+			wantCode:  http.StatusNoContent,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin":  "https://app.example.com",
+				"Access-Control-Allow-Methods": "GET, POST",
+				"Access-Control-Allow-Headers": "Content-Type, X-Api-Key",
+				"Access-Control-Max-Age":       "600",
+			},
+		},
+		{
+			name:      "05 - Preflight from disallowed origin",
+			method:    http.MethodOptions,
+			origin:    "https://evil.test",
+			preflight: true,
+			wantCode:  http.StatusForbidden,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/api/v1/foo/1", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status code = %d, want %d", w.Code, tt.wantCode)
+			}
+			for k, v := range tt.wantHeader {
+				if got := w.Header().Get(k); got != v {
+					t.Errorf("%s = %q, want %q", k, got, v)
+				}
+			}
+		})
+	}
+}
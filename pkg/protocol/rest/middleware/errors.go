@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// writeError writes an error in the same JSON envelope the gateway uses for gRPC errors.
+func writeError(w http.ResponseWriter, r *http.Request, code int, status, reason, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":       code,
+			"status":     status,
+			"message":    message,
+			"reason":     reason,
+			"request_id": GetReqID(r.Context()),
+		},
+	})
+}
@@ -6,19 +6,40 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/logger"
 )
 
-func AddLogger(logger *zap.Logger, h http.Handler) http.Handler {
+// User returns the common name of the verified TLS client certificate, or "" for anonymous callers.
+func User(r *http.Request) string {
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		return r.TLS.VerifiedChains[0][0].Subject.CommonName
+	}
+	return ""
+}
+
+// AddLogger logs requests at debug level and stores l, enriched with the request ID, method,
+// route and user, in the request context for logger.FromContext. It must run after AddRequestID.
+func AddLogger(l *zap.Logger, h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
+		id := GetReqID(ctx)
+
+		fields := []zap.Field{
+			zap.String("request_id", id),
+			zap.String("http.method", r.Method),
+			zap.String("http.route", routeOf(r)),
+		}
+		if user := User(r); len(user) > 0 {
+			fields = append(fields, zap.String("user", user))
+		}
+		r = r.WithContext(logger.NewContext(ctx, l.With(fields...)))
 
 		if r.Header.Get("X-Liveness-Probe") == "Healthz" {
 			h.ServeHTTP(w, r)
 			return
 		}
 
-		id := GetReqID(ctx)
-
 		var scheme string
 		if r.TLS != nil {
 			scheme = "https"
@@ -31,7 +52,7 @@ func AddLogger(logger *zap.Logger, h http.Handler) http.Handler {
 		userAgent := r.UserAgent()
 		uri := strings.Join([]string{scheme, "://", r.Host, r.RequestURI}, "")
 
-		logger.Debug("Request started",
+		l.Debug("Request started",
 			zap.String("request-id", id),
 			zap.String("http-scheme", scheme),
 			zap.String("http-proto", proto),
@@ -45,7 +66,7 @@ func AddLogger(logger *zap.Logger, h http.Handler) http.Handler {
 
 		h.ServeHTTP(w, r)
 
-		logger.Debug("Request completed",
+		l.Debug("Request completed",
 			zap.String("request-id", id),
 			zap.String("http-scheme", scheme),
 			zap.String("http-proto", proto),
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/logger"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/payload"
+)
+
+// maxCapturedPayload bounds the body kept in memory for logging; larger bodies are only summarised.
+const maxCapturedPayload = 1 << 20
+
+// payloadRecorder keeps a copy of the response body while writing it through.
+type payloadRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+	size   int
+}
+
+func (r *payloadRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *payloadRecorder) Write(b []byte) (int, error) {
+	r.size += len(b)
+	if r.body.Len()+len(b) <= maxCapturedPayload {
+		r.body.Write(b)
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *payloadRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func renderPayload(p *payload.Policy, body []byte, size int, md protoreflect.MessageDescriptor) string {
+	if size > maxCapturedPayload {
+		return fmt.Sprintf("<over %d bytes, not logged>", maxCapturedPayload)
+	}
+	return p.JSON(body, md)
+}
+
+// AddPayloadLogging logs the redacted request and response bodies of a sample of requests,
+// as configured by policy. Fields marked (sensitive) are redacted from the bodies of the
+// gateway routes stored by AddRoute. It must run after AddLogger to log with the
+// request-scoped logger.
+func AddPayloadLogging(policy *payload.ReloadablePolicy, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := policy.Load()
+		if !p.Sampled() {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		var reqBody []byte
+		if r.Body != nil && r.Body != http.NoBody {
+			b, err := ioutil.ReadAll(io.LimitReader(r.Body, maxCapturedPayload+1))
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "INVALID_ARGUMENT", "INVALID_ARGUMENT", "Failed to read request body")
+				return
+			}
+			reqBody = b
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(b), r.Body), r.Body}
+		}
+
+		rec := &payloadRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+
+		var reqType, respType protoreflect.MessageDescriptor
+		if route := matchedRoute(r); route != nil {
+			reqType = route.Request
+			if rec.status < http.StatusBadRequest {
+				respType = route.Response
+			}
+		}
+		fields := []zap.Field{zap.Int("http.status", rec.status)}
+		if len(reqBody) > 0 {
+			fields = append(fields, zap.String("http.request", renderPayload(p, reqBody, len(reqBody), reqType)))
+		}
+		if rec.size > 0 {
+			fields = append(fields, zap.String("http.response", renderPayload(p, rec.body.Bytes(), rec.size, respType)))
+		}
+		logger.FromContext(r.Context()).Named("payload").Info("Payload", fields...)
+	})
+}
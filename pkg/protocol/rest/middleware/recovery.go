@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"go.uber.org/zap"
+
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/metrics"
+)
+
+// AddRecovery turns a panicking handler into a 500 response in the gateway's error
+// format and logs the stack trace with the request ID.
+func AddRecovery(logger *zap.Logger, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+
+			metrics.Panics.WithLabelValues("http").Inc()
+
+			id := GetReqID(r.Context())
+			logger.Error("Recovered from panic",
+				zap.String("request-id", id),
+				zap.String("http-method", r.Method),
+				zap.String("uri", r.RequestURI),
+				zap.String("panic", fmt.Sprint(p)),
+				zap.ByteString("stack", debug.Stack()),
+			)
+
+			writeError(w, r, http.StatusInternalServerError, "INTERNAL", "PANIC",
+				fmt.Sprintf("Internal error (correlation id: %s)", id))
+		}()
+
+		h.ServeHTTP(w, r)
+	})
+}
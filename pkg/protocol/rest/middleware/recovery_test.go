@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestAddRecovery(t *testing.T) {
+	h := AddRequestID(AddRecovery(zap.NewNop(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var foo *struct{ Title string }
+		_, _ = w.Write([]byte(foo.Title))
+	})))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/foo", nil)
+	r.Header.Set("X-Request-Id", "req-1")
+
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status code = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	var body struct {
+		Error struct {
+			Status    string `json:"status"`
+			RequestID string `json:"request_id"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON body %q: %v", w.Body.String(), err)
+	}
+	if body.Error.Status != "INTERNAL" || body.Error.RequestID != "req-1" {
+		t.Errorf("unexpected body %s", w.Body.String())
+	}
+}
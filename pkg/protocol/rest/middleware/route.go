@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// OtherRoute labels requests that match no known route.
+const OtherRoute = "other"
+
+// OtherMethod labels requests with a non-standard HTTP method.
+const OtherMethod = "OTHER"
+
+var standardMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// Route is a path template served by the HTTP server, e.g. GET /api/v1/foo/{id}.
+// An empty Method matches every method.
+type Route struct {
+	Method string
+	Path   string
+	// Request and Response are the messages of the gRPC method behind a gateway route,
+	// nil for other routes.
+	Request  protoreflect.MessageDescriptor
+	Response protoreflect.MessageDescriptor
+}
+
+type routeKey struct{}
+
+type compiledRoute struct {
+	Route
+	segments []string
+}
+
+// Routes maps request paths to the template of the route serving them, so metrics,
+// request logs and span names have a bounded set of labels.
+type Routes struct {
+	routes []compiledRoute
+}
+
+func NewRoutes(routes []Route) *Routes {
+	rt := &Routes{}
+	for _, r := range routes {
+		rt.routes = append(rt.routes, compiledRoute{Route: r, segments: strings.Split(r.Path, "/")})
+	}
+	return rt
+}
+
+// Match returns the path template of the route serving method and path, or OtherRoute.
+func (rt *Routes) Match(method, path string) string {
+	if r := rt.match(method, path); r != nil {
+		return r.Path
+	}
+	return OtherRoute
+}
+
+// match returns the route serving method and path, preferring the route with the most
+// literal segments, or nil. Preflight OPTIONS requests match the routes of every method.
+func (rt *Routes) match(method, path string) *Route {
+	segments := strings.Split(path, "/")
+	var best *Route
+	bestLiterals := -1
+	for i := range rt.routes {
+		r := &rt.routes[i]
+		if len(r.Method) > 0 && r.Method != method && method != http.MethodOptions {
+			continue
+		}
+		if literals, ok := matchSegments(r.segments, segments); ok && literals > bestLiterals {
+			best, bestLiterals = &r.Route, literals
+		}
+	}
+	return best
+}
+
+// matchSegments matches path segments against a template where {name} stands for
+// any one non-empty segment, and returns the number of literal segments.
+func matchSegments(template, path []string) (int, bool) {
+	if len(template) != len(path) {
+		return 0, false
+	}
+	literals := 0
+	for i, t := range template {
+		switch {
+		case strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}"):
+			if len(path[i]) == 0 {
+				return 0, false
+			}
+		case t == path[i]:
+			literals++
+		default:
+			return 0, false
+		}
+	}
+	return literals, true
+}
+
+// AddRoute stores the matched route in the request context for the metrics, logging
+// and tracing middleware. It must run before them.
+func AddRoute(routes *Routes, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), routeKey{}, routes.match(r.Method, r.URL.Path))
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// matchedRoute returns the route stored by AddRoute, or nil.
+func matchedRoute(r *http.Request) *Route {
+	route, _ := r.Context().Value(routeKey{}).(*Route)
+	return route
+}
+
+// routeOf returns the path template of the route stored by AddRoute, or OtherRoute.
+func routeOf(r *http.Request) string {
+	if route := matchedRoute(r); route != nil {
+		return route.Path
+	}
+	return OtherRoute
+}
+
+// methodOf returns r's method, or OtherMethod for non-standard methods.
+func methodOf(r *http.Request) string {
+	if standardMethods[r.Method] {
+		return r.Method
+	}
+	return OtherMethod
+}
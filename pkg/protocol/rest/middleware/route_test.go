@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRoutes_Match(t *testing.T) {
+	routes := NewRoutes([]Route{
+		{Method: http.MethodGet, Path: "/api/v1/foo/all"},
+		{Method: http.MethodPut, Path: "/api/v1/foo/{foo.id}"},
+		{Method: http.MethodGet, Path: "/api/v1/foo/{id}"},
+		{Path: "/healthz"},
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   string
+	}{
+		{name: "01 - Path parameter", method: http.MethodGet, path: "/api/v1/foo/42", want: "/api/v1/foo/{id}"},
+		{name: "02 - Literal wins", method: http.MethodGet, path: "/api/v1/foo/all", want: "/api/v1/foo/all"},
+		{name: "03 - Matched by method", method: http.MethodPut, path: "/api/v1/foo/42", want: "/api/v1/foo/{foo.id}"},
+		{name: "04 - Any method", method: http.MethodPost, path: "/healthz", want: "/healthz"},
+		{name: "05 - Unknown API path", method: http.MethodGet, path: "/api/x7f3a9", want: OtherRoute},
+		{name: "06 - Unknown method", method: http.MethodDelete, path: "/api/v1/foo/all", want: OtherRoute},
+		{name: "07 - Empty parameter", method: http.MethodGet, path: "/api/v1/foo/", want: OtherRoute},
+		{name: "08 - Preflight", method: http.MethodOptions, path: "/api/v1/foo/all", want: "/api/v1/foo/all"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := routes.Match(tt.method, tt.path); got != tt.want {
+				t.Errorf("Routes.Match() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddRoute(t *testing.T) {
+	routes := NewRoutes([]Route{{Method: http.MethodGet, Path: "/api/v1/foo/{id}"}})
+	var route, method string
+	h := AddRoute(routes, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, method = routeOf(r), methodOf(r)
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PURGE", "/api/v1/foo/42", nil))
+	if route != OtherRoute || method != OtherMethod {
+		t.Errorf("route, method = %s, %s, want %s, %s", route, method, OtherRoute, OtherMethod)
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/foo/42", nil))
+	if route != "/api/v1/foo/{id}" || method != http.MethodGet {
+		t.Errorf("route, method = %s, %s", route, method)
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// TimeoutHeader lets HTTP clients set a deadline as a Go duration, e.g. "2.5s".
+// Grpc-Timeout is also honoured by the gateway itself.
+const TimeoutHeader = "X-Request-Timeout"
+
+// AddTimeout applies the X-Request-Timeout deadline to the request context, which
+// the gateway forwards as the deadline of the gRPC call.
+func AddTimeout(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if v := r.Header.Get(TimeoutHeader); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				writeError(w, r, http.StatusBadRequest, "INVALID_ARGUMENT", "INVALID_ARGUMENT",
+					"Invalid "+TimeoutHeader+" header: "+v)
+				return
+			}
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			r = r.WithContext(ctx)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// AddTracing starts a server span per request, continuing any incoming traceparent.
+// Spans are named after the route stored by AddRoute so IDs do not inflate span name cardinality.
+func AddTracing(h http.Handler) http.Handler {
+	return otelhttp.NewHandler(h, "http",
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return r.URL.Path != "/healthz" && r.URL.Path != "/readyz"
+		}),
+		otelhttp.WithSpanNameFormatter(func(operation string, r *http.Request) string {
+			return methodOf(r) + " " + routeOf(r)
+		}),
+	)
+}
@@ -0,0 +1,62 @@
+package rest
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/wingkwong/go-grpc-boilerplate/api/swagger"
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/rest/middleware"
+)
+
+// staticPaths are the endpoints served next to the gateway.
+var staticPaths = []string{"/healthz", "/readyz", "/openapi.json", "/openapi.v3.json", "/docs", docsScriptPath}
+
+// routes lists the gateway's path templates and the messages of their gRPC methods, taken
+// from the embedded OpenAPI document generated from the same HTTP rules, followed by the
+// static endpoints.
+func routes() ([]middleware.Route, error) {
+	var doc openapi2.T
+	if err := json.Unmarshal(swagger.FooServiceV1, &doc); err != nil {
+		return nil, err
+	}
+	var rs []middleware.Route
+	for path, item := range doc.Paths {
+		for method, op := range item.Operations() {
+			r := middleware.Route{Method: method, Path: path}
+			if m := grpcMethod(op.OperationID); m != nil {
+				r.Request, r.Response = m.Input(), m.Output()
+			}
+			rs = append(rs, r)
+		}
+	}
+	// Sorted, so routes of different methods sharing a path shape are matched deterministically.
+	sort.Slice(rs, func(i, j int) bool {
+		if rs[i].Path != rs[j].Path {
+			return rs[i].Path < rs[j].Path
+		}
+		return rs[i].Method < rs[j].Method
+	})
+	for _, path := range staticPaths {
+		rs = append(rs, middleware.Route{Path: path})
+	}
+	return rs, nil
+}
+
+// grpcMethod returns the method behind an operation ID such as FooService_Update2, which
+// protoc-gen-swagger numbers when a method has additional bindings, or nil.
+func grpcMethod(operationID string) protoreflect.MethodDescriptor {
+	i := strings.LastIndex(operationID, "_")
+	if i < 0 {
+		return nil
+	}
+	sd := v1.File_foo_service_proto.Services().ByName(protoreflect.Name(operationID[:i]))
+	if sd == nil {
+		return nil
+	}
+	return sd.Methods().ByName(protoreflect.Name(strings.TrimRight(operationID[i+1:], "0123456789")))
+}
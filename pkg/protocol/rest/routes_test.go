@@ -0,0 +1,64 @@
+package rest
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func Test_grpcMethod(t *testing.T) {
+	tests := []struct {
+		name        string
+		operationID string
+		want        protoreflect.FullName
+	}{
+		{
+			name:        "01 - Method",
+			operationID: "FooService_Create",
+			want:        "v1.FooService.Create",
+		},
+		{
+			name:        "02 - Additional binding",
+			operationID: "FooService_Update2",
+			want:        "v1.FooService.Update",
+		},
+		{
+			name:        "03 - Unknown service",
+			operationID: "BarService_Create",
+		},
+		{
+			name:        "04 - Not an operation ID",
+			operationID: "healthz",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got protoreflect.FullName
+			if m := grpcMethod(tt.operationID); m != nil {
+				got = m.FullName()
+			}
+			if got != tt.want {
+				t.Errorf("grpcMethod() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_routes(t *testing.T) {
+	rs, err := routes()
+	if err != nil {
+		t.Fatalf("routes() error = %v", err)
+	}
+	for _, r := range rs {
+		if r.Method == "PUT" && r.Path == "/api/v1/foo/{foo.id}" {
+			if r.Request == nil || r.Request.FullName() != "v1.UpdateRequest" {
+				t.Errorf("Request = %v, want v1.UpdateRequest", r.Request)
+			}
+			if r.Response == nil || r.Response.FullName() != "v1.UpdateResponse" {
+				t.Errorf("Response = %v, want v1.UpdateResponse", r.Response)
+			}
+			return
+		}
+	}
+	t.Errorf("routes() = %v, want a PUT /api/v1/foo/{foo.id} route", rs)
+}
@@ -2,47 +2,112 @@ package rest
 
 import (
 	"context"
+	"errors"
 	"net/http"
-	"os"
-	"os/signal"
-	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
-	"go.uber.org/zap"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 
 	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
 	"github.com/wingkwong/go-grpc-boilerplate/pkg/logger"
+	grpcmiddleware "github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/grpc/middleware"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/payload"
 	"github.com/wingkwong/go-grpc-boilerplate/pkg/protocol/rest/middleware"
 )
 
-func RunServer(ctx context.Context, grpcPort, httpPort string) error {
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+// DialOptions are the options the gateway's connection to the gRPC server must be dialed with.
+func DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(otelgrpc.StreamClientInterceptor()),
+	}
+}
+
+// Options configures the endpoints and middleware installed by NewHandler.
+type Options struct {
+	// CORS is optional; nil disables CORS.
+	CORS *middleware.ReloadableCORS
+	// DocsScriptURL is the Redoc bundle loaded by /docs, the embedded copy if empty.
+	DocsScriptURL string
+	// Payloads is optional; nil disables payload logging.
+	Payloads *payload.ReloadablePolicy
+	// TrustedProxies are the proxies whose X-Forwarded-For entries identify the client.
+	TrustedProxies grpcmiddleware.Proxies
+	// Draining, if set, reports that shutdown has started; /readyz fails from then on.
+	Draining func() bool
+}
+
+// NewHandler returns the HTTP/REST gateway for the gRPC server behind conn, together with
+// the health and API documentation endpoints, wrapped in the REST middleware stack.
+func NewHandler(ctx context.Context, conn *grpc.ClientConn, dbPing func(context.Context) error, options Options) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithProtoErrorHandler(errorHandler),
+		// ReadAll's /api/v1/foo/all is registered after, and must take precedence over, Read's /api/v1/foo/{id}.
+		runtime.WithLastMatchWins(),
+		runtime.WithIncomingHeaderMatcher(headerMatcher),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeaderMatcher),
+		runtime.WithMetadata(requestIDAnnotator),
+		runtime.WithMetadata(userAnnotator),
+		runtime.WithMetadata(peerAnnotator(options.TrustedProxies)),
+	)
 
-	mux := runtime.NewServeMux()
-	opts := []grpc.DialOption{grpc.WithInsecure()}
-	if err := v1.RegisterFooServiceHandlerFromEndpoint(ctx, mux, "localhost:"+grpcPort, opts); err != nil {
-		logger.Log.Fatal("Failed to start HTTP gateway", zap.String("reason", err.Error()))
+	if err := v1.RegisterFooServiceHandler(ctx, mux, conn); err != nil {
+		return nil, err
+	}
+	if err := v1.RegisterAuditServiceHandler(ctx, mux, conn); err != nil {
+		return nil, err
 	}
 
-	srv := &http.Server{
-		Addr:    ":" + httpPort,
-		Handler: middleware.AddRequestID(middleware.AddLogger(logger.Log, mux)),
+	root := http.NewServeMux()
+	root.HandleFunc("/healthz", healthzHandler)
+	root.Handle("/readyz", readyzHandler(dbPing, conn, options.Draining))
+	if options.CORS == nil {
+		options.CORS = middleware.NewReloadableCORS(middleware.CORS{})
+	}
+	if err := registerDocs(root, options.DocsScriptURL); err != nil {
+		return nil, err
 	}
+	root.Handle("/", mux)
 
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
-	go func() {
-		for range c {
-			// handle ^C
-		}
-		_, cancel := context.WithTimeout(ctx, 5*time.Second)
-		defer cancel()
+	var h http.Handler = middleware.AddTimeout(root)
+	if options.Payloads != nil {
+		h = middleware.AddPayloadLogging(options.Payloads, h)
+	}
+	h = middleware.AddCORS(options.CORS, middleware.AddCompression(middleware.AddLogger(logger.Named("rest"), h)))
+	// Recovery wraps every middleware after AddRequestID, so a panic in any of them becomes a
+	// 500 carrying the request ID; metrics wrap it to count that 500.
+	h = middleware.AddMetrics(middleware.AddRecovery(logger.Log, h))
+	rs, err := routes()
+	if err != nil {
+		return nil, err
+	}
+	return middleware.AddRoute(middleware.NewRoutes(rs), middleware.AddTracing(middleware.AddRequestID(h))), nil
+}
+
+type Server struct {
+	srv *http.Server
+}
 
-		_ = srv.Shutdown(ctx)
-	}()
+func NewServer(httpPort string, handler http.Handler) *Server {
+	return &Server{
+		srv: &http.Server{
+			Addr:    ":" + httpPort,
+			Handler: handler,
+		},
+	}
+}
 
+func (s *Server) Serve() error {
 	logger.Log.Info("Starting HTTP/REST Gateway...")
-	return srv.ListenAndServe()
+	if err := s.srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting requests and waits for in-flight ones to complete.
+func (s *Server) Shutdown(ctx context.Context) error {
+	logger.Log.Warn("Shutting down HTTP/REST Gateway...")
+	return s.srv.Shutdown(ctx)
 }
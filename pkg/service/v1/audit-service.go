@@ -0,0 +1,95 @@
+package v1
+
+import (
+	"context"
+	"crypto/subtle"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/apierrors"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/audit"
+)
+
+const defaultAuditPageSize = 100
+
+type auditServiceServer struct {
+	store *audit.Store
+	token string
+}
+
+// NewAuditServiceServer serves searches of store to callers presenting the admin token.
+// An empty token rejects every search.
+func NewAuditServiceServer(store *audit.Store, token string) v1.AuditServiceServer {
+	return &auditServiceServer{store: store, token: token}
+}
+
+func (s *auditServiceServer) authorize(ctx context.Context) error {
+	if len(s.token) == 0 {
+		return apierrors.PermissionDenied("Audit search is disabled: no admin token is configured")
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		got := strings.TrimPrefix(v, "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) == 1 {
+			return nil
+		}
+	}
+	return apierrors.Unauthenticated("Audit search requires the admin token as 'authorization: Bearer <token>'")
+}
+
+func (s *auditServiceServer) Search(ctx context.Context, req *v1.SearchAuditRequest) (*v1.SearchAuditResponse, error) {
+	if err := checkAPI(req.ApiVersion); err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	q := audit.Query{Actor: req.Actor, Limit: int(req.PageSize)}
+	if q.Limit == 0 {
+		q.Limit = defaultAuditPageSize
+	}
+	if req.StartTime != nil {
+		q.Start = req.StartTime.AsTime()
+	}
+	if req.EndTime != nil {
+		q.End = req.EndTime.AsTime()
+	}
+	if len(req.PageToken) > 0 {
+		id, err := strconv.ParseInt(req.PageToken, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, apierrors.InvalidArgument("Invalid page token", apierrors.FieldViolation{Field: "page_token", Description: "must be a next_page_token returned by Search"})
+		}
+		q.AfterID = id
+	}
+
+	entries, err := s.store.Search(ctx, q)
+	if err != nil {
+		return nil, dbError(ctx, "Failed to search audit entries", err)
+	}
+
+	resp := &v1.SearchAuditResponse{ApiVersion: apiVersion}
+	for _, e := range entries {
+		resp.Entries = append(resp.Entries, &v1.AuditEntry{
+			Id:        e.ID,
+			Time:      timestamppb.New(e.Time),
+			Actor:     e.Actor,
+			Tenant:    e.Tenant,
+			Peer:      e.Peer,
+			RequestId: e.RequestID,
+			Method:    e.Method,
+			FooId:     e.FooID,
+			Code:      e.Code,
+			Latency:   durationpb.New(e.Latency),
+		})
+	}
+	if len(entries) == q.Limit {
+		resp.NextPageToken = strconv.FormatInt(entries[len(entries)-1].ID, 10)
+	}
+	return resp, nil
+}
@@ -0,0 +1,119 @@
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/audit"
+)
+
+func Test_auditServiceServer_Search(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("[Error] '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	s := NewAuditServiceServer(audit.NewStore(db), "t0k3n")
+
+	admin := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer t0k3n"))
+	columns := []string{"ID", "Time", "Actor", "Tenant", "Peer", "RequestID", "Method", "FooID", "Code", "LatencyMicros"}
+	tm := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		s        v1.AuditServiceServer
+		ctx      context.Context
+		req      *v1.SearchAuditRequest
+		mock     func()
+		wantCode codes.Code
+		want     func(t *testing.T, resp *v1.SearchAuditResponse)
+	}{
+		{
+			name:     "01 - No admin token configured",
+			s:        NewAuditServiceServer(audit.NewStore(db), ""),
+			ctx:      admin,
+			req:      &v1.SearchAuditRequest{ApiVersion: "v1"},
+			mock:     func() {},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "02 - Wrong token",
+			s:        s,
+			ctx:      metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer nope")),
+			req:      &v1.SearchAuditRequest{ApiVersion: "v1"},
+			mock:     func() {},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "03 - Full page by actor",
+			s:    s,
+			ctx:  admin,
+			req:  &v1.SearchAuditRequest{ApiVersion: "v1", Actor: "jane", PageSize: 1, PageToken: "41"},
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM Audit WHERE `Actor` = \\? AND `ID` > \\? ORDER BY `ID` LIMIT \\?").
+					WithArgs("jane", 41, 1).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(42, tm, "jane", "acme", "10.0.0.1", "req-1", "/v1.FooService/Delete", 7, "OK", 1500))
+			},
+			want: func(t *testing.T, resp *v1.SearchAuditResponse) {
+				if len(resp.Entries) != 1 || resp.Entries[0].FooId != 7 || resp.Entries[0].Latency.AsDuration() != 1500*time.Microsecond {
+					t.Errorf("unexpected entries %v", resp.Entries)
+				}
+				if resp.NextPageToken != "42" {
+					t.Errorf("NextPageToken = %q, want 42", resp.NextPageToken)
+				}
+			},
+		},
+		{
+			name: "04 - Last page by time range",
+			s:    s,
+			ctx:  admin,
+			req: &v1.SearchAuditRequest{
+				ApiVersion: "v1",
+				StartTime:  timestamppb.New(tm),
+				EndTime:    timestamppb.New(tm.Add(time.Hour)),
+			},
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM Audit WHERE `Time` >= \\? AND `Time` < \\? ORDER BY `ID` LIMIT \\?").
+					WithArgs(tm, tm.Add(time.Hour), defaultAuditPageSize).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			want: func(t *testing.T, resp *v1.SearchAuditResponse) {
+				if len(resp.Entries) != 0 || resp.NextPageToken != "" {
+					t.Errorf("unexpected response %v", resp)
+				}
+			},
+		},
+		{
+			name:     "05 - Invalid page token",
+			s:        s,
+			ctx:      admin,
+			req:      &v1.SearchAuditRequest{ApiVersion: "v1", PageToken: "abc"},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			resp, err := tt.s.Search(tt.ctx, tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("auditServiceServer.Search() error = %v, want code %v", err, tt.wantCode)
+			}
+			if tt.want != nil {
+				tt.want(t, resp)
+			}
+		})
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
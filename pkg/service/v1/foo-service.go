@@ -3,16 +3,23 @@ package v1
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strconv"
 	"time"
 
-	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"go.uber.org/zap"
 	"google.golang.org/protobuf/types/known/timestamppb"
+
+	v1 "github.com/wingkwong/go-grpc-boilerplate/pkg/api/v1"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/apierrors"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/logger"
+	"github.com/wingkwong/go-grpc-boilerplate/pkg/metrics"
 )
 
 const (
 	apiVersion = "v1"
+
+	fooResourceType = "Foo"
 )
 
 type fooServiceServer struct {
@@ -23,24 +30,53 @@ func NewFooServiceServer(db *sql.DB) v1.FooServiceServer {
 	return &fooServiceServer{db: db}
 }
 
-func (s *fooServiceServer) checkAPI(api string) error {
+func checkAPI(api string) error {
 	if len(api) > 0 && apiVersion != api {
-		return status.Errorf(codes.Unimplemented,
-			"[Error] Unsupported API version: service API version '%s', but got '%s'", apiVersion, api)
+		return apierrors.UnsupportedAPIVersion(apiVersion, api)
+	}
+	return nil
+}
+
+// checkFoo rejects a missing foo, as the validation interceptor does before handlers run.
+func checkFoo(foo *v1.Foo) error {
+	if foo == nil {
+		return apierrors.InvalidArgument("Request failed validation", apierrors.FieldViolation{Field: "foo", Description: "value is required"})
 	}
 	return nil
 }
 
+// dbError logs a failed database call with the request-scoped logger and converts it into a
+// status error. Calls cut short by the request deadline or by the client are reported as such
+// rather than as internal errors; internal errors are logged by apierrors.Internal.
+func dbError(ctx context.Context, msg string, err error) error {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		logger.FromContext(ctx).Warn(msg, zap.String("reason", apierrors.ReasonDeadlineExceeded), zap.Error(err))
+		return apierrors.DeadlineExceeded(msg + ": deadline exceeded")
+	case context.Canceled:
+		logger.FromContext(ctx).Info(msg, zap.String("reason", apierrors.ReasonCanceled), zap.Error(err))
+		return apierrors.Canceled(msg + ": request canceled")
+	}
+	return apierrors.Internal(ctx, apierrors.ReasonInternal, msg, err)
+}
+
 func (s *fooServiceServer) connect(ctx context.Context) (*sql.Conn, error) {
 	c, err := s.db.Conn(ctx)
+	if err != nil && ctx.Err() != nil {
+		return nil, dbError(ctx, "Failed to connect to database", err)
+	}
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "[Error] Failed to connect to database: "+err.Error())
+		return nil, apierrors.Unavailable(ctx, apierrors.ReasonDatabaseUnavailable, "Failed to connect to database", err)
 	}
 	return c, nil
 }
 
 func (s *fooServiceServer) Create(ctx context.Context, req *v1.CreateRequest) (*v1.CreateResponse, error) {
-	if err := s.checkAPI(req.ApiVersion); err != nil {
+	if err := checkAPI(req.ApiVersion); err != nil {
+		return nil, err
+	}
+
+	if err := checkFoo(req.GetFoo()); err != nil {
 		return nil, err
 	}
 
@@ -52,19 +88,21 @@ func (s *fooServiceServer) Create(ctx context.Context, req *v1.CreateRequest) (*
 
 	curTime := time.Now()
 
-	res, err := c.ExecContext(
-		ctx,
+	res, err := execContext(
+		ctx, c, "INSERT",
 		"INSERT INTO Foo(`Title`, `Desc`, `CreatedBy`, `UpdatedBy`, `CreatedAt`, `UpdatedAt`) VALUES(?, ?, ?, ?, ?, ?)",
-		req.Foo.Title, req.Foo.Desc, req.Foo.SysFields.CreatedBy, req.Foo.SysFields.UpdatedBy, curTime, curTime)
+		req.Foo.Title, req.Foo.Desc, req.Foo.GetSysFields().GetCreatedBy(), req.Foo.GetSysFields().GetUpdatedBy(), curTime, curTime)
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "[Error] Failed to insert into record: "+err.Error())
+		return nil, dbError(ctx, "Failed to insert into record", err)
 	}
 
 	id, err := res.LastInsertId()
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "[Error] Failed to retrieve last inserted id:  "+err.Error())
+		return nil, dbError(ctx, "Failed to retrieve last inserted id", err)
 	}
 
+	metrics.FoosCreated.Inc()
+
 	return &v1.CreateResponse{
 		ApiVersion: apiVersion,
 		Id:         id,
@@ -72,7 +110,7 @@ func (s *fooServiceServer) Create(ctx context.Context, req *v1.CreateRequest) (*
 }
 
 func (s *fooServiceServer) Read(ctx context.Context, req *v1.ReadRequest) (*v1.ReadResponse, error) {
-	if err := s.checkAPI(req.ApiVersion); err != nil {
+	if err := checkAPI(req.ApiVersion); err != nil {
 		return nil, err
 	}
 
@@ -83,17 +121,17 @@ func (s *fooServiceServer) Read(ctx context.Context, req *v1.ReadRequest) (*v1.R
 	defer c.Close()
 
 	id := req.Id
-	rows, err := c.QueryContext(ctx, "SELECT `ID`, `Title`, `Desc`, `CreatedBy`, `UpdatedBy`, `CreatedAt`, `UpdatedAt` FROM Foo WHERE `ID` = ?", id)
+	rows, err := queryContext(ctx, c, "SELECT", "SELECT `ID`, `Title`, `Desc`, `CreatedBy`, `UpdatedBy`, `CreatedAt`, `UpdatedAt` FROM Foo WHERE `ID` = ?", id)
 	if err != nil {
-		return nil, status.Errorf(codes.Unknown, "[Error] Failed to select data from Foo by Id %d : "+err.Error(), id)
+		return nil, dbError(ctx, fmt.Sprintf("Failed to select data from Foo by Id %d", id), err)
 	}
 	defer rows.Close()
 
 	if !rows.Next() {
 		if err := rows.Err(); err != nil {
-			return nil, status.Error(codes.Unknown, "[Error] Failed to retrieve data from Foo: "+err.Error())
+			return nil, dbError(ctx, "Failed to retrieve data from Foo", err)
 		}
-		return nil, status.Errorf(codes.NotFound, "[Error] Failed to find Id : %d", id)
+		return nil, apierrors.NotFound(fooResourceType, strconv.FormatInt(id, 10))
 	}
 
 	var foo v1.Foo
@@ -104,14 +142,14 @@ func (s *fooServiceServer) Read(ctx context.Context, req *v1.ReadRequest) (*v1.R
 
 	// TODO: probably use jmoiron/sqlx to assign to a struct
 	if err := rows.Scan(&foo.Id, &foo.Title, &foo.Desc, &foo.SysFields.CreatedBy, &foo.SysFields.UpdatedBy, &CreatedAt, &UpdatedAt); err != nil {
-		return nil, status.Error(codes.Unknown, "[Error] Failed to retrieve values from Foo rows : "+err.Error())
+		return nil, dbError(ctx, "Failed to retrieve values from Foo rows", err)
 	}
 
 	foo.SysFields.CreatedAt = timestamppb.New(CreatedAt)
 	foo.SysFields.UpdatedAt = timestamppb.New(UpdatedAt)
 
 	if rows.Next() {
-		return nil, status.Errorf(codes.Unknown, "[Error] multiple rows with the same id :'%d'", id)
+		return nil, dbError(ctx, "Failed to read Foo", fmt.Errorf("multiple rows with the same id '%d'", id))
 	}
 
 	return &v1.ReadResponse{
@@ -121,19 +159,34 @@ func (s *fooServiceServer) Read(ctx context.Context, req *v1.ReadRequest) (*v1.R
 }
 
 func (s *fooServiceServer) ReadAll(ctx context.Context, req *v1.ReadAllRequest) (*v1.ReadAllResponse, error) {
-	if err := s.checkAPI(req.ApiVersion); err != nil {
+	if err := checkAPI(req.ApiVersion); err != nil {
 		return nil, err
 	}
 
+	var afterID int64
+	if len(req.PageToken) > 0 {
+		id, err := strconv.ParseInt(req.PageToken, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, apierrors.InvalidArgument("Invalid page token", apierrors.FieldViolation{Field: "page_token", Description: "must be a next_page_token returned by ReadAll"})
+		}
+		afterID = id
+	}
+
 	c, err := s.connect(ctx)
 	if err != nil {
 		return nil, err
 	}
 	defer c.Close()
 
-	rows, err := c.QueryContext(ctx, "SELECT `ID`, `Title`, `Desc`, `CreatedBy`, `UpdatedBy`, `CreatedAt`, `UpdatedAt` FROM Foo")
+	query := "SELECT `ID`, `Title`, `Desc`, `CreatedBy`, `UpdatedBy`, `CreatedAt`, `UpdatedAt` FROM Foo WHERE `ID` > ? ORDER BY `ID`"
+	args := []interface{}{afterID}
+	if req.PageSize > 0 {
+		query += " LIMIT ?"
+		args = append(args, req.PageSize)
+	}
+	rows, err := queryContext(ctx, c, "SELECT", query, args...)
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "[Error] Failed to retrieve all data from Foo: "+err.Error())
+		return nil, dbError(ctx, "Failed to retrieve all data from Foo", err)
 	}
 	defer rows.Close()
 
@@ -142,35 +195,43 @@ func (s *fooServiceServer) ReadAll(ctx context.Context, req *v1.ReadAllRequest)
 
 	fooList := []*v1.Foo{}
 	for rows.Next() {
-		foo := new(v1.Foo)
+		foo := &v1.Foo{SysFields: &v1.SystemFields{}}
 		if err := rows.Scan(&foo.Id, &foo.Title, &foo.Desc, &foo.SysFields.CreatedBy, &foo.SysFields.UpdatedBy, &CreatedAt, &UpdatedAt); err != nil {
-			return nil, status.Error(codes.Unknown, "[ERROR] Failed to retrieve field values from Foo: "+err.Error())
+			return nil, dbError(ctx, "Failed to retrieve field values from Foo", err)
 		}
 
 		foo.SysFields.CreatedAt = timestamppb.New(CreatedAt)
 		if err != nil {
-			return nil, status.Error(codes.Unknown, "[ERROR] Field createdAt has invalid format: "+err.Error())
+			return nil, dbError(ctx, "Field createdAt has invalid format", err)
 		}
 
 		foo.SysFields.UpdatedAt = timestamppb.New(UpdatedAt)
 		if err != nil {
-			return nil, status.Error(codes.Unknown, "[ERROR] Field updatedAt has invalid format: "+err.Error())
+			return nil, dbError(ctx, "Field updatedAt has invalid format", err)
 		}
 		fooList = append(fooList, foo)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, status.Error(codes.Unknown, "[ERROR] Failed to retrieve data from Foo: "+err.Error())
+		return nil, dbError(ctx, "Failed to retrieve data from Foo", err)
 	}
 
-	return &v1.ReadAllResponse{
+	resp := &v1.ReadAllResponse{
 		ApiVersion: apiVersion,
 		Foos:       fooList,
-	}, nil
+	}
+	if req.PageSize > 0 && len(fooList) == int(req.PageSize) {
+		resp.NextPageToken = strconv.FormatInt(fooList[len(fooList)-1].Id, 10)
+	}
+	return resp, nil
 }
 
 func (s *fooServiceServer) Update(ctx context.Context, req *v1.UpdateRequest) (*v1.UpdateResponse, error) {
-	if err := s.checkAPI(req.ApiVersion); err != nil {
+	if err := checkAPI(req.ApiVersion); err != nil {
+		return nil, err
+	}
+
+	if err := checkFoo(req.GetFoo()); err != nil {
 		return nil, err
 	}
 
@@ -182,20 +243,22 @@ func (s *fooServiceServer) Update(ctx context.Context, req *v1.UpdateRequest) (*
 
 	updatedAt := time.Now()
 
-	res, err := c.ExecContext(ctx, "UPDATE Foo SET `Title` = ?, `Desc` = ?, `UpdatedAt` = ? WHERE `ID` = ?", req.Foo.Title, req.Foo.Desc, updatedAt, req.Foo.Id)
+	res, err := execContext(ctx, c, "UPDATE", "UPDATE Foo SET `Title` = ?, `Desc` = ?, `UpdatedAt` = ? WHERE `ID` = ?", req.Foo.Title, req.Foo.Desc, updatedAt, req.Foo.Id)
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "[Error] Failed to update Foo : "+err.Error())
+		return nil, dbError(ctx, "Failed to update Foo", err)
 	}
 
 	rows, err := res.RowsAffected()
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "[Error] Failed to retrieve rows affected value :  "+err.Error())
+		return nil, dbError(ctx, "Failed to retrieve rows affected value", err)
 	}
 
 	if rows == 0 {
-		return nil, status.Errorf(codes.NotFound, "[Error] Failed to update Foo with id : %d", req.Foo.Id)
+		return nil, apierrors.NotFound(fooResourceType, strconv.FormatInt(req.Foo.Id, 10))
 	}
 
+	metrics.FoosUpdated.Add(float64(rows))
+
 	return &v1.UpdateResponse{
 		ApiVersion: apiVersion,
 		Count:      rows,
@@ -203,7 +266,7 @@ func (s *fooServiceServer) Update(ctx context.Context, req *v1.UpdateRequest) (*
 }
 
 func (s *fooServiceServer) Delete(ctx context.Context, req *v1.DeleteRequest) (*v1.DeleteResponse, error) {
-	if err := s.checkAPI(req.ApiVersion); err != nil {
+	if err := checkAPI(req.ApiVersion); err != nil {
 		return nil, err
 	}
 
@@ -215,20 +278,22 @@ func (s *fooServiceServer) Delete(ctx context.Context, req *v1.DeleteRequest) (*
 
 	id := req.Id
 
-	res, err := c.ExecContext(ctx, "DELETE FROM Foo WHERE `ID` = ?", id)
+	res, err := execContext(ctx, c, "DELETE", "DELETE FROM Foo WHERE `ID` = ?", id)
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "[Error] Failed to delete Foo : "+err.Error())
+		return nil, dbError(ctx, "Failed to delete Foo", err)
 	}
 
 	rows, err := res.RowsAffected()
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "[Error] Failed to retrieve rows affected value :  "+err.Error())
+		return nil, dbError(ctx, "Failed to retrieve rows affected value", err)
 	}
 
 	if rows == 0 {
-		return nil, status.Errorf(codes.NotFound, "[Error] Failed to delete Foo with id : %d", id)
+		return nil, apierrors.NotFound(fooResourceType, strconv.FormatInt(id, 10))
 	}
 
+	metrics.FoosDeleted.Add(float64(rows))
+
 	return &v1.DeleteResponse{
 		ApiVersion: apiVersion,
 		Count:      rows,
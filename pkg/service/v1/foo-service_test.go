@@ -108,7 +108,7 @@ func Test_fooServiceServer_Create(t *testing.T) {
 			},
 			mock: func() {
 				mock.ExpectExec("INSERT INTO Foo").
-					WithArgs("title", "description", "foo", "foo").
+					WithArgs("title", "description", "foo", "foo", AnyTime{}, AnyTime{}).
 					WillReturnError(errors.New("INSERT failed"))
 			},
 			wantErr: true,
@@ -132,11 +132,46 @@ func Test_fooServiceServer_Create(t *testing.T) {
 			},
 			mock: func() {
 				mock.ExpectExec("INSERT INTO Foo").
-					WithArgs("title", "description", "foo", "foo").
+					WithArgs("title", "description", "foo", "foo", AnyTime{}, AnyTime{}).
 					WillReturnResult(sqlmock.NewErrorResult(errors.New("LastInsertId failed")))
 			},
 			wantErr: true,
 		},
+		{
+			name: "05 - Missing foo",
+			s:    s,
+			args: args{
+				ctx: ctx,
+				req: &v1.CreateRequest{
+					ApiVersion: "v1",
+				},
+			},
+			mock:    func() {},
+			wantErr: true,
+		},
+		{
+			name: "06 - Missing sys fields",
+			s:    s,
+			args: args{
+				ctx: ctx,
+				req: &v1.CreateRequest{
+					ApiVersion: "v1",
+					Foo: &v1.Foo{
+						Title: "title",
+						Desc:  "description",
+					},
+				},
+			},
+			mock: func() {
+				mock.ExpectExec("INSERT INTO Foo").
+					WithArgs("title", "description", "", "", AnyTime{}, AnyTime{}).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+			},
+			want: &v1.CreateResponse{
+				ApiVersion: "v1",
+				Id:         2,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -268,6 +303,92 @@ func Test_fooServiceServer_Read(t *testing.T) {
 	}
 }
 
+func Test_fooServiceServer_ReadAll(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("[Error] '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	s := NewFooServiceServer(db)
+
+	curTime := time.Now()
+	columns := []string{"ID", "Title", "Desc", "CreatedBy", "UpdatedBy", "CreatedAt", "UpdatedAt"}
+
+	tests := []struct {
+		name          string
+		req           *v1.ReadAllRequest
+		mock          func()
+		wantIDs       []int64
+		wantPageToken string
+		wantErr       bool
+	}{
+		{
+			name: "01 - All",
+			req:  &v1.ReadAllRequest{ApiVersion: "v1"},
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, "title", "description", "foo", "foo", curTime, curTime).
+					AddRow(2, "title", "description", "foo", "foo", curTime, curTime)
+				mock.ExpectQuery("SELECT (.+) FROM Foo WHERE `ID` > \\? ORDER BY `ID`$").WithArgs(0).WillReturnRows(rows)
+			},
+			wantIDs: []int64{1, 2},
+		},
+		{
+			name: "02 - Full page",
+			req:  &v1.ReadAllRequest{ApiVersion: "v1", PageSize: 2, PageToken: "3"},
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(4, "title", "description", "foo", "foo", curTime, curTime).
+					AddRow(6, "title", "description", "foo", "foo", curTime, curTime)
+				mock.ExpectQuery("SELECT (.+) FROM Foo WHERE `ID` > \\? ORDER BY `ID` LIMIT \\?").WithArgs(3, 2).WillReturnRows(rows)
+			},
+			wantIDs:       []int64{4, 6},
+			wantPageToken: "6",
+		},
+		{
+			name: "03 - Last page",
+			req:  &v1.ReadAllRequest{ApiVersion: "v1", PageSize: 2, PageToken: "6"},
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(7, "title", "description", "foo", "foo", curTime, curTime)
+				mock.ExpectQuery("SELECT (.+) FROM Foo WHERE `ID` > \\? ORDER BY `ID` LIMIT \\?").WithArgs(6, 2).WillReturnRows(rows)
+			},
+			wantIDs: []int64{7},
+		},
+		{
+			name:    "04 - Invalid page token",
+			req:     &v1.ReadAllRequest{ApiVersion: "v1", PageToken: "abc"},
+			mock:    func() {},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := s.ReadAll(ctx, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("fooServiceServer.ReadAll() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+
+			var ids []int64
+			for _, foo := range got.Foos {
+				ids = append(ids, foo.Id)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) || got.NextPageToken != tt.wantPageToken {
+				t.Errorf("fooServiceServer.ReadAll() = %v, %q, want %v, %q", ids, got.NextPageToken, tt.wantIDs, tt.wantPageToken)
+			}
+		})
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
 func Test_fooServiceServer_Update(t *testing.T) {
 	ctx := context.Background()
 	db, mock, err := sqlmock.New()
@@ -0,0 +1,49 @@
+package v1
+
+import (
+	"context"
+	"database/sql"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/wingkwong/go-grpc-boilerplate/pkg/service/v1")
+
+func startSQLSpan(ctx context.Context, operation, query string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "sql "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemMySQL,
+			semconv.DBOperationKey.String(operation),
+			semconv.DBStatementKey.String(query),
+		),
+	)
+}
+
+func endSQLSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// execContext runs an Exec statement inside a child span.
+func execContext(ctx context.Context, c *sql.Conn, operation, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startSQLSpan(ctx, operation, query)
+	res, err := c.ExecContext(ctx, query, args...)
+	endSQLSpan(span, err)
+	return res, err
+}
+
+// queryContext runs a query inside a child span. The span covers statement
+// execution; row iteration is attributed to the calling RPC span.
+func queryContext(ctx context.Context, c *sql.Conn, operation, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := startSQLSpan(ctx, operation, query)
+	rows, err := c.QueryContext(ctx, query, args...)
+	endSQLSpan(span, err)
+	return rows, err
+}
@@ -8,4 +8,20 @@ CREATE TABLE `Foo` (
   `UpdatedAt` timestamp NOT NULL,
   PRIMARY KEY (`ID`),
   UNIQUE KEY `ID_UNIQUE` (`ID`)
-);
\ No newline at end of file
+);
+
+CREATE TABLE `Audit` (
+  `ID` bigint(20) NOT NULL AUTO_INCREMENT,
+  `Time` timestamp(6) NOT NULL,
+  `Actor` varchar(256) NOT NULL,
+  `Tenant` varchar(128) NOT NULL,
+  `Peer` varchar(256) NOT NULL,
+  `RequestID` varchar(128) NOT NULL,
+  `Method` varchar(256) NOT NULL,
+  `FooID` bigint(20) NOT NULL,
+  `Code` varchar(32) NOT NULL,
+  `LatencyMicros` bigint(20) NOT NULL,
+  PRIMARY KEY (`ID`),
+  KEY `Audit_Actor_Time` (`Actor`, `Time`),
+  KEY `Audit_Time` (`Time`)
+);